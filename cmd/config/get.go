@@ -8,16 +8,26 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
+var showSecrets bool
+
 var getConfigCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Display currently configured options",
-	Long:  `Display currently configured options`,
+	Long: `Display currently configured options.
+The options are shown with the active profile applied, along with the name of the profile and the
+source of the team token. The team token and proxy password are masked unless --show-secrets is set.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		bytes, _ := yaml.Marshal(config.GetConfig())
+		c := *config.GetConfig()
+		c.Profiles = nil
+		if !showSecrets {
+			c = c.Masked()
+		}
+		bytes, _ := yaml.Marshal(c)
 		fmt.Println(string(bytes))
 	},
 }
 
 func init() {
+	getConfigCmd.Flags().BoolVar(&showSecrets, "show-secrets", false, "show the team token and proxy password")
 	ConfigCmd.AddCommand(getConfigCmd)
 }
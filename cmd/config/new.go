@@ -4,18 +4,38 @@ import (
 	"os"
 
 	"github.com/mupuri/go-tfdr/internal/config"
+	"github.com/mupuri/go-tfdr/internal/config/file"
 	"github.com/spf13/cobra"
 )
 
+var token string
+var orgName string
+var address string
+var force bool
+
 var newConfigCmd = &cobra.Command{
 	Use:   "new",
 	Short: "Generates a terraform state copy config file in $HOME/.tfdr",
-	Long:  `Generates a terraform state copy config config file in $HOME/.tfdr`,
-	Run: func(cmd *cobra.Command, args []string) {
-		config.GenerateConfig(os.Stdin)
+	Long: `Generates a terraform state copy config config file in $HOME/.tfdr, or the file passed with --config.
+With --profile, only the named profile is added or updated and the rest of the file is kept.
+Settings that are not passed as flags are prompted for. When stdin is not a terminal, --token
+and --org are required and an existing config file is only overwritten with --force.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return config.GenerateConfig(os.Stdin, config.GenerateOptions{
+			Token:       token,
+			OrgName:     orgName,
+			Address:     address,
+			Profile:     config.GetConfig().Profile,
+			Force:       force,
+			Interactive: file.IsTerminal(os.Stdin),
+		})
 	},
 }
 
 func init() {
+	newConfigCmd.Flags().StringVar(&token, "token", "", "Terraform team token")
+	newConfigCmd.Flags().StringVar(&orgName, "org", "", "Terraform organization name")
+	newConfigCmd.Flags().StringVar(&address, "address", "", "Terraform Cloud or Enterprise address (default app.terraform.io)")
+	newConfigCmd.Flags().BoolVarP(&force, "force", "f", false, "overwrite an existing config file without asking")
 	ConfigCmd.AddCommand(newConfigCmd)
 }
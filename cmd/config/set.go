@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mupuri/go-tfdr/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var setConfigCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Sets a single option in the config file",
+	Long: fmt.Sprintf(`Sets a single option in the config file, or in the active profile if --profile is set.
+The rest of the config file is kept as it is. Keys are %s.`, strings.Join(config.Keys(), ", ")),
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfgFile, err := config.SetValue(args[0], args[1])
+		if err != nil {
+			return err
+		}
+		if profile := config.GetConfig().Profile; profile != "" {
+			fmt.Printf("Set %s of profile %s in %s\n", args[0], profile, cfgFile)
+		} else {
+			fmt.Printf("Set %s in %s\n", args[0], cfgFile)
+		}
+		return nil
+	},
+}
+
+func init() {
+	ConfigCmd.AddCommand(setConfigCmd)
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"github.com/mupuri/go-tfdr/internal/api"
+	"github.com/mupuri/go-tfdr/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var validateConfigCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Checks the configured token and organization against Terraform Cloud",
+	Long: `Checks that the configured Terraform Cloud or Enterprise host can be reached, that the team token
+is accepted and that it has access to the configured organization.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.ValidateConfig(); err != nil {
+			return err
+		}
+		return api.ValidateConnection(config.GetConfig())
+	},
+}
+
+func init() {
+	ConfigCmd.AddCommand(validateConfigCmd)
+}
@@ -0,0 +1,11 @@
+package filter
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var FilterCmd = &cobra.Command{
+	Use:   "filter",
+	Short: "Filter config options",
+	Long:  `Filter config options`,
+}
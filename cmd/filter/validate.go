@@ -0,0 +1,82 @@
+package filter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/mupuri/go-tfdr/internal/api"
+	"github.com/mupuri/go-tfdr/internal/config"
+	"github.com/mupuri/go-tfdr/internal/filter"
+	"github.com/mupuri/go-tfdr/internal/tfdrerrors"
+	"github.com/spf13/cobra"
+)
+
+var workspaceName string
+var filterConfigFile string
+var output string
+var varFiles, vars []string
+
+var validateFilterCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Checks a filter config against the current state of a workspace",
+	Long: `Checks a filter config against the current state of a workspace or state file.
+Reports filters that match no resources or more than one, global resource types that are not in the
+state, renames that collide with the address of another resource, and filters that copy instances of
+one resource with different names or providers. Matching no resources, colliding renames and
+conflicting filters are errors, and the command exits with a non-zero code when there are errors.
+The workspace is either the name of a workspace in the configured Terraform Cloud organization,
+a tfc://<org>/<workspace> address, the file://<path> address of a local state file, or the
+s3://<bucket>/<key> address of a state file in an S3 compatible bucket.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(filterConfigFile) == 0 {
+			return errors.New("filterConfigFile file is required")
+		}
+		if len(workspaceName) == 0 {
+			return errors.New("workspaceName file is required")
+		}
+		if output != "text" && output != "json" {
+			return fmt.Errorf("Invalid output %q. Expected text or json", output)
+		}
+		if !api.UsesTerraformCloud(workspaceName) {
+			return nil
+		}
+		return config.ValidateConfig()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// the report explains the errors, the usage would only bury it
+		cmd.SilenceUsage = true
+		values, err := filter.ReadVars(varFiles, vars)
+		if err != nil {
+			return err
+		}
+		report, err := api.LintFilter(workspaceName, filterConfigFile, values)
+		if err != nil {
+			return err
+		}
+
+		if output == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				return err
+			}
+		} else {
+			report.Write(os.Stdout)
+		}
+		if report.Errors > 0 {
+			return tfdrerrors.ErrInvalidFilter{Errors: report.Errors}
+		}
+		return nil
+	},
+}
+
+func init() {
+	validateFilterCmd.PersistentFlags().StringVarP(&workspaceName, "workspaceName", "w", "", "workspace name or state address to check the filter config against")
+	validateFilterCmd.PersistentFlags().StringVarP(&filterConfigFile, "filterConfigFile", "f", "", "file with filter config to check (.json, .yaml, .yml or .hcl)")
+	validateFilterCmd.PersistentFlags().StringVar(&output, "output", "text", "output format, text or json")
+	validateFilterCmd.PersistentFlags().StringArrayVar(&vars, "var", nil, "value of a filter config variable, as <name>=<value>")
+	validateFilterCmd.PersistentFlags().StringArrayVar(&varFiles, "var-file", nil, "file with values of filter config variables (.json, .yaml, .yml or .hcl)")
+	FilterCmd.AddCommand(validateFilterCmd)
+}
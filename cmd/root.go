@@ -4,6 +4,7 @@ import (
 	"log"
 
 	cfg "github.com/mupuri/go-tfdr/cmd/config"
+	"github.com/mupuri/go-tfdr/cmd/filter"
 	state "github.com/mupuri/go-tfdr/cmd/state"
 	"github.com/mupuri/go-tfdr/internal/config"
 	"github.com/mupuri/go-tfdr/internal/logging"
@@ -35,17 +36,20 @@ func Execute(version string) error {
 }
 
 var cfgFile string
+var profile string
 
 func init() {
 	cobra.OnInitialize(initConfig)
 	rootCmd.DisableAutoGenTag = true
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "config profile to use (default $TFDR_PROFILE)")
 	rootCmd.AddCommand(cfg.ConfigCmd)
 	rootCmd.AddCommand(state.StateCmd)
+	rootCmd.AddCommand(filter.FilterCmd)
 	rootCmd.AddCommand(docCmd)
 }
 
 func initConfig() {
-	config.InitConfig(cfgFile)
+	config.InitConfigWithProfile(cfgFile, profile)
 	logging.InitLogger()
 }
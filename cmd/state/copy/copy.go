@@ -2,20 +2,35 @@ package copy
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/mupuri/go-tfdr/internal/api"
 	"github.com/mupuri/go-tfdr/internal/config"
+	"github.com/mupuri/go-tfdr/internal/filter"
 	"github.com/spf13/cobra"
 )
 
 var originalWorkspaceName string
 var newWorkspaceName string
 var filterConfigFile string
+var dryRun bool
+var backupDir string
+var noBackup bool
+var varFiles, vars []string
+var sourceProfile, sourceAddress, sourceOrgName, sourceToken string
+var destProfile, destAddress, destOrgName, destToken string
+var sourceConfig, destConfig *config.Configuration
 
 var CopyStateCmd = &cobra.Command{
 	Use:   "copy",
 	Short: "Copies state from one workspace to another",
-	Long:  `Copies state from one workspace to another`,
+	Long: `Copies state from one workspace to another.
+Workspaces are either names of workspaces in the configured Terraform Cloud organization,
+tfc://<org>/<workspace> addresses, file://<path> addresses of local state files, or
+s3://<bucket>/<key> addresses of state files in S3 compatible buckets.
+The --source-* and --dest-* flags select a config profile or override the configured Terraform Cloud
+host, organization and token for the original and new workspace, to copy state between organizations
+and hosts.`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(filterConfigFile) == 0 {
 			return errors.New("filterConfigFile file is required")
@@ -26,15 +41,71 @@ var CopyStateCmd = &cobra.Command{
 		if len(newWorkspaceName) == 0 {
 			return errors.New("newWorkspaceName is required")
 		}
-		return config.ValidateConfig()
+		var err error
+		if sourceConfig, err = sideConfig(sourceProfile, sourceAddress, sourceOrgName, sourceToken); err != nil {
+			return fmt.Errorf("Invalid source configuration. Error: %w", err)
+		}
+		if destConfig, err = sideConfig(destProfile, destAddress, destOrgName, destToken); err != nil {
+			return fmt.Errorf("Invalid destination configuration. Error: %w", err)
+		}
+		if api.UsesTerraformCloud(originalWorkspaceName) {
+			if err := sourceConfig.Validate(); err != nil {
+				return fmt.Errorf("Invalid source configuration. Error: %w", err)
+			}
+		}
+		if api.UsesTerraformCloud(newWorkspaceName) {
+			if err := destConfig.Validate(); err != nil {
+				return fmt.Errorf("Invalid destination configuration. Error: %w", err)
+			}
+		}
+		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return api.CopyTFState(originalWorkspaceName, newWorkspaceName, filterConfigFile)
+		values, err := filter.ReadVars(varFiles, vars)
+		if err != nil {
+			return err
+		}
+		return api.CopyTFState(originalWorkspaceName, newWorkspaceName, filterConfigFile, api.Options{
+			DryRun:            dryRun,
+			BackupDir:         backupDir,
+			NoBackup:          noBackup,
+			SourceConfig:      sourceConfig,
+			DestinationConfig: destConfig,
+			Vars:              values,
+		})
 	},
 }
 
+// sideConfig returns the configuration of one side of the copy. The profile, if any, replaces the
+// active profile and the flags override both.
+func sideConfig(profile, address, orgName, token string) (*config.Configuration, error) {
+	c := config.GetConfig()
+	if profile != "" {
+		var err error
+		if c, err = config.GetProfile(profile); err != nil {
+			return nil, err
+		}
+	} else if err := config.ValidateConfig(); errors.Is(err, config.ErrProfileNotFound) {
+		return nil, err
+	}
+	return c.Override(address, orgName, token), nil
+}
+
 func init() {
-	CopyStateCmd.PersistentFlags().StringVarP(&originalWorkspaceName, "originalWorkspaceName", "o", "", "workspace to copy state from")
-	CopyStateCmd.PersistentFlags().StringVarP(&newWorkspaceName, "newWorkspaceName", "n", "", "workspace to copy state to")
-	CopyStateCmd.PersistentFlags().StringVarP(&filterConfigFile, "filterConfigFile", "f", "", "file with filter config with resources to copy")
+	CopyStateCmd.PersistentFlags().StringVarP(&originalWorkspaceName, "originalWorkspaceName", "o", "", "workspace or state address to copy state from")
+	CopyStateCmd.PersistentFlags().StringVarP(&newWorkspaceName, "newWorkspaceName", "n", "", "workspace or state address to copy state to")
+	CopyStateCmd.PersistentFlags().StringVarP(&filterConfigFile, "filterConfigFile", "f", "", "file with filter config with resources to copy (.json, .yaml, .yml or .hcl)")
+	CopyStateCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print the resources that would be copied without creating a new state version")
+	CopyStateCmd.PersistentFlags().StringVar(&backupDir, "backup-dir", "", "directory to back up the state to before it is changed (default $HOME/.tfdr/backups)")
+	CopyStateCmd.PersistentFlags().BoolVar(&noBackup, "no-backup", false, "do not back up the state before it is changed")
+	CopyStateCmd.PersistentFlags().StringArrayVar(&vars, "var", nil, "value of a filter config variable, as <name>=<value>")
+	CopyStateCmd.PersistentFlags().StringArrayVar(&varFiles, "var-file", nil, "file with values of filter config variables (.json, .yaml, .yml or .hcl)")
+	CopyStateCmd.PersistentFlags().StringVar(&sourceProfile, "source-profile", "", "config profile of the original workspace")
+	CopyStateCmd.PersistentFlags().StringVar(&sourceAddress, "source-address", "", "Terraform Cloud or Enterprise address of the original workspace")
+	CopyStateCmd.PersistentFlags().StringVar(&sourceOrgName, "source-org", "", "organization of the original workspace")
+	CopyStateCmd.PersistentFlags().StringVar(&sourceToken, "source-token", "", "team token for the original workspace")
+	CopyStateCmd.PersistentFlags().StringVar(&destProfile, "dest-profile", "", "config profile of the new workspace")
+	CopyStateCmd.PersistentFlags().StringVar(&destAddress, "dest-address", "", "Terraform Cloud or Enterprise address of the new workspace")
+	CopyStateCmd.PersistentFlags().StringVar(&destOrgName, "dest-org", "", "organization of the new workspace")
+	CopyStateCmd.PersistentFlags().StringVar(&destToken, "dest-token", "", "team token for the new workspace")
 }
@@ -5,17 +5,25 @@ import (
 
 	"github.com/mupuri/go-tfdr/internal/api"
 	"github.com/mupuri/go-tfdr/internal/config"
+	"github.com/mupuri/go-tfdr/internal/filter"
 	"github.com/spf13/cobra"
 )
 
 var workspaceName string
 var filterConfigFile string
+var dryRun bool
+var backupDir string
+var noBackup bool
+var varFiles, vars []string
 
 // DeleteStateCmd &
 var DeleteStateCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Deletes selected resources from TF cloud workspace state",
-	Long:  `Deletes selected resources from TF cloud workspace state`,
+	Long: `Deletes selected resources from TF cloud workspace state.
+The workspace is either the name of a workspace in the configured Terraform Cloud organization,
+a tfc://<org>/<workspace> address, the file://<path> address of a local state file, or the
+s3://<bucket>/<key> address of a state file in an S3 compatible bucket.`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(filterConfigFile) == 0 {
 			return errors.New("filterConfigFile file is required")
@@ -23,14 +31,31 @@ var DeleteStateCmd = &cobra.Command{
 		if len(workspaceName) == 0 {
 			return errors.New("workspaceName file is required")
 		}
+		if !api.UsesTerraformCloud(workspaceName) {
+			return nil
+		}
 		return config.ValidateConfig()
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return api.DeleteTFStateResources(workspaceName, filterConfigFile)
+		values, err := filter.ReadVars(varFiles, vars)
+		if err != nil {
+			return err
+		}
+		return api.DeleteTFStateResources(workspaceName, filterConfigFile, api.Options{
+			DryRun:    dryRun,
+			BackupDir: backupDir,
+			NoBackup:  noBackup,
+			Vars:      values,
+		})
 	},
 }
 
 func init() {
-	DeleteStateCmd.PersistentFlags().StringVarP(&workspaceName, "workspaceName", "w", "", "workspace name")
-	DeleteStateCmd.PersistentFlags().StringVarP(&filterConfigFile, "filterConfigFile", "f", "", "file with filter config with resources to copy")
+	DeleteStateCmd.PersistentFlags().StringVarP(&workspaceName, "workspaceName", "w", "", "workspace name or state address")
+	DeleteStateCmd.PersistentFlags().StringVarP(&filterConfigFile, "filterConfigFile", "f", "", "file with filter config with resources to copy (.json, .yaml, .yml or .hcl)")
+	DeleteStateCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print the resources that would be deleted without creating a new state version")
+	DeleteStateCmd.PersistentFlags().StringVar(&backupDir, "backup-dir", "", "directory to back up the state to before it is changed (default $HOME/.tfdr/backups)")
+	DeleteStateCmd.PersistentFlags().BoolVar(&noBackup, "no-backup", false, "do not back up the state before it is changed")
+	DeleteStateCmd.PersistentFlags().StringArrayVar(&vars, "var", nil, "value of a filter config variable, as <name>=<value>")
+	DeleteStateCmd.PersistentFlags().StringArrayVar(&varFiles, "var-file", nil, "file with values of filter config variables (.json, .yaml, .yml or .hcl)")
 }
@@ -0,0 +1,58 @@
+package restore
+
+import (
+	"errors"
+
+	"github.com/mupuri/go-tfdr/internal/api"
+	"github.com/mupuri/go-tfdr/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var workspaceName string
+var stateVersionID string
+var backupFile string
+var autoApprove bool
+var dryRun bool
+var backupDir string
+var noBackup bool
+
+// RestoreStateCmd &
+var RestoreStateCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restores TF cloud workspace state to a previous state version or a local backup",
+	Long: `Restores TF cloud workspace state to a previous state version or a local backup.
+The restored state is uploaded with a serial one above the current state and the lineage of the current state.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(workspaceName) == 0 {
+			return errors.New("workspaceName is required")
+		}
+		if len(stateVersionID) == 0 && len(backupFile) == 0 {
+			return errors.New("one of stateVersion or file is required")
+		}
+		if len(stateVersionID) != 0 && len(backupFile) != 0 {
+			return errors.New("only one of stateVersion or file can be set")
+		}
+		if !api.UsesTerraformCloud(workspaceName) {
+			return nil
+		}
+		return config.ValidateConfig()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return api.RestoreTFState(workspaceName, stateVersionID, backupFile, api.Options{
+			DryRun:      dryRun,
+			AutoApprove: autoApprove,
+			BackupDir:   backupDir,
+			NoBackup:    noBackup,
+		})
+	},
+}
+
+func init() {
+	RestoreStateCmd.PersistentFlags().StringVarP(&workspaceName, "workspaceName", "w", "", "workspace name or state address")
+	RestoreStateCmd.PersistentFlags().StringVarP(&stateVersionID, "stateVersion", "s", "", "id of the state version to restore")
+	RestoreStateCmd.PersistentFlags().StringVarP(&backupFile, "file", "f", "", "local state backup file to restore")
+	RestoreStateCmd.PersistentFlags().BoolVarP(&autoApprove, "yes", "y", false, "restore without asking for confirmation")
+	RestoreStateCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print the changes the restore would make without creating a new state version")
+	RestoreStateCmd.PersistentFlags().StringVar(&backupDir, "backup-dir", "", "directory to back up the state to before it is changed (default $HOME/.tfdr/backups)")
+	RestoreStateCmd.PersistentFlags().BoolVar(&noBackup, "no-backup", false, "do not back up the state before it is changed")
+}
@@ -3,6 +3,7 @@ package state
 import (
 	"github.com/mupuri/go-tfdr/cmd/state/copy"
 	"github.com/mupuri/go-tfdr/cmd/state/delete"
+	"github.com/mupuri/go-tfdr/cmd/state/restore"
 	"github.com/spf13/cobra"
 )
 
@@ -15,4 +16,5 @@ var StateCmd = &cobra.Command{
 func init() {
 	StateCmd.AddCommand(copy.CopyStateCmd)
 	StateCmd.AddCommand(delete.DeleteStateCmd)
+	StateCmd.AddCommand(restore.RestoreStateCmd)
 }
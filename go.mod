@@ -3,12 +3,16 @@ module github.com/mupuri/go-tfdr
 go 1.14
 
 require (
+	github.com/aws/aws-sdk-go v1.35.37
 	github.com/eiannone/keyboard v0.0.0-20200508000154-caf4b762e807
 	github.com/hashicorp/go-tfe v0.10.2
+	github.com/hashicorp/hcl v1.0.0
 	github.com/jarcoal/httpmock v1.0.6
 	github.com/sirupsen/logrus v1.7.0
 	github.com/spf13/cobra v1.1.0
 	github.com/spf13/viper v1.7.0
 	github.com/stretchr/testify v1.6.1
+	golang.org/x/term v0.0.0-20201126162022-7de9c90e9dd1
 	gopkg.in/yaml.v2 v2.3.0
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/mupuri/go-tfdr/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+var httpClient = &http.Client{}
+
+// newTFEClient returns a client for the Terraform Cloud or Terraform Enterprise address of the configuration
+func newTFEClient(c *config.Configuration) (*tfe.Client, error) {
+	client, err := newHTTPClient(c)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot create tfe client. Err: %v", err)
+	}
+
+	tfeConfig := &tfe.Config{
+		Address:    tfeAddress(c.TerraformAddress),
+		HTTPClient: client,
+		Token:      c.TerraformTeamToken,
+	}
+
+	tfeClient, err := tfe.NewClient(tfeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot create tfe client. Err: %v", err)
+	}
+	return tfeClient, nil
+}
+
+// tfeAddress adds the https scheme to addresses configured as a bare hostname
+func tfeAddress(address string) string {
+	if address == "" || strings.Contains(address, "://") {
+		return address
+	}
+	return "https://" + address
+}
+
+// newHTTPClient returns httpClient unless the configuration sets a CA bundle, disables certificate
+// verification or sets a proxy, in which case it returns a client with its own transport
+func newHTTPClient(c *config.Configuration) (*http.Client, error) {
+	if c.CACertFile == "" && !c.InsecureSkipVerify && c.HTTPProxy == "" {
+		return httpClient, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+	if c.InsecureSkipVerify {
+		logrus.Warn("TLS certificate verification is disabled")
+	}
+
+	if c.CACertFile != "" {
+		pem, err := ioutil.ReadFile(c.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read CA bundle. Err: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No certificates found in CA bundle %s", c.CACertFile)
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	if c.HTTPProxy != "" {
+		proxy, err := url.Parse(c.HTTPProxy)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("Invalid HTTP proxy %q", c.HTTPProxy)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/mupuri/go-tfdr/internal/config"
+	"github.com/mupuri/go-tfdr/internal/logging"
+	"github.com/mupuri/go-tfdr/internal/testutils"
+	"github.com/stretchr/testify/suite"
+)
+
+type ClientSuite struct {
+	suite.Suite
+	server *httptest.Server
+}
+
+func (s *ClientSuite) SetupTest() {
+	os.Setenv("TF_TEAM_TOKEN", "test")
+	os.Setenv("TF_ORG_NAME", "team")
+	config.InitConfig("")
+	logging.InitLogger()
+	os.MkdirAll(testStateDir, 0755)
+	s.server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(204)
+	}))
+}
+
+func (s *ClientSuite) TearDownTest() {
+	s.server.Close()
+	os.RemoveAll(testStateDir)
+	os.Unsetenv("TF_TEAM_TOKEN")
+	os.Unsetenv("TF_ORG_NAME")
+}
+
+func (s *ClientSuite) writeServerCA() string {
+	caFile := filepath.Join(testStateDir, "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.server.Certificate().Raw})
+	s.Require().NoError(ioutil.WriteFile(caFile, cert, 0644))
+	return caFile
+}
+
+func (s *ClientSuite) TestTFEAddress() {
+	cases := []struct {
+		address  string
+		expected string
+	}{
+		{"", ""},
+		{"tfe.example.com", "https://tfe.example.com"},
+		{"https://tfe.example.com", "https://tfe.example.com"},
+		{"http://localhost:8080", "http://localhost:8080"},
+	}
+
+	for _, c := range cases {
+		s.Equal(c.expected, tfeAddress(c.address), c.address)
+	}
+}
+
+func (s *ClientSuite) TestNewHTTPClient() {
+	emptyCA := filepath.Join(testStateDir, "empty.pem")
+	s.Require().NoError(ioutil.WriteFile(emptyCA, []byte("not a certificate"), 0644))
+
+	cases := []struct {
+		configuration config.Configuration
+		shared        bool
+		shouldErr     bool
+		errMessage    string
+	}{
+		{config.Configuration{}, true, false, "Test default http client failed"},
+		{config.Configuration{InsecureSkipVerify: true}, false, false, "Test insecure skip verify http client failed"},
+		{config.Configuration{HTTPProxy: "http://proxy.example.com:3128"}, false, false, "Test proxy http client failed"},
+		{config.Configuration{HTTPProxy: "proxy"}, false, true, "Test invalid proxy failed"},
+		{config.Configuration{CACertFile: s.writeServerCA()}, false, false, "Test CA bundle http client failed"},
+		{config.Configuration{CACertFile: filepath.Join(testStateDir, "missing.pem")}, false, true, "Test missing CA bundle failed"},
+		{config.Configuration{CACertFile: emptyCA}, false, true, "Test CA bundle without certificates failed"},
+	}
+
+	for _, c := range cases {
+		client, err := newHTTPClient(&c.configuration)
+		if c.shouldErr {
+			s.Error(err, c.errMessage)
+			continue
+		}
+		s.NoError(err, c.errMessage)
+		s.Equal(c.shared, client == httpClient, c.errMessage)
+	}
+}
+
+func (s *ClientSuite) TestNewTFEClientCABundle() {
+	config.GetConfig().TerraformAddress = s.server.URL
+	_, err := newTFEClient(config.GetConfig())
+	s.Error(err, "Test tfe client with untrusted certificate failed")
+
+	config.GetConfig().CACertFile = s.writeServerCA()
+	_, err = newTFEClient(config.GetConfig())
+	s.NoError(err, "Test tfe client with CA bundle failed")
+
+	config.GetConfig().CACertFile = ""
+	config.GetConfig().InsecureSkipVerify = true
+	_, err = newTFEClient(config.GetConfig())
+	s.NoError(err, "Test tfe client with insecure skip verify failed")
+}
+
+func (s *ClientSuite) TestTFEAddressUsedByStore() {
+	config.GetConfig().TerraformAddress = "tfe.example.com"
+	httpmock.ActivateNonDefault(httpClient)
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "https://tfe.example.com/api/v2/ping", httpmock.NewStringResponder(204, ""))
+	httpmock.RegisterResponder("GET", "https://tfe.example.com/api/v2/organizations/team/workspaces/test", testutils.NewResponder("test", "workspaces", ""))
+	httpmock.RegisterResponder("GET", "https://tfe.example.com/api/v2/workspaces/test/current-state-version", httpmock.NewStringResponder(404, ""))
+
+	store, err := NewStateStore("test")
+	s.NoError(err)
+	state, err := store.Read()
+	s.NoError(err)
+	s.Nil(state)
+	s.Equal(1, httpmock.GetCallCountInfo()["GET https://tfe.example.com/api/v2/ping"])
+
+	_, err = store.Read()
+	s.NoError(err)
+	s.Equal(1, httpmock.GetCallCountInfo()["GET https://tfe.example.com/api/v2/ping"], "Test store reuses its tfe client failed")
+}
+
+func TestClientSuite(t *testing.T) {
+	suite.Run(t, new(ClientSuite))
+}
@@ -3,14 +3,26 @@ package api
 import (
 	"fmt"
 
+	"github.com/mupuri/go-tfdr/internal/diff"
 	"github.com/mupuri/go-tfdr/internal/filter"
 	"github.com/mupuri/go-tfdr/internal/models"
 	"github.com/mupuri/go-tfdr/internal/tfdrerrors"
 )
 
-// CopyTFState &
-func CopyTFState(origWorkspaceName string, newWorkspaceName string, filterConfigFileName string) error {
-	oldState, err := pullTFState(origWorkspaceName)
+// CopyTFState copies the filtered state of the original state store to the empty new state store.
+// Stores are addressed as described in NewStateStore, using opts.SourceConfig and opts.DestinationConfig
+// for Terraform Cloud workspaces.
+func CopyTFState(origAddress string, newAddress string, filterConfigFileName string, opts Options) error {
+	origStore, err := newStateStore(origAddress, configOrDefault(opts.SourceConfig))
+	if err != nil {
+		return err
+	}
+	newStore, err := newStateStore(newAddress, configOrDefault(opts.DestinationConfig))
+	if err != nil {
+		return err
+	}
+
+	oldState, oldStateBytes, err := readState(origStore)
 	if err != nil {
 		return tfdrerrors.ErrReadState{Err: err}
 	}
@@ -18,30 +30,80 @@ func CopyTFState(origWorkspaceName string, newWorkspaceName string, filterConfig
 		return tfdrerrors.ErrSourceIsEmpty{}
 	}
 
-	newResources, err := filter.StateFilter(oldState.Resources, filter.CopyResourceFilterFunc, filterConfigFileName)
+	filterConfig, err := filter.ReadFilterConfigWithVars(filterConfigFileName, opts.Vars)
+	if err != nil {
+		return fmt.Errorf("Unable to filter resources from state. Error: %v", err)
+	}
+	result, err := filter.FilterStateResources(oldState.Resources, filter.CopyResourceFilterFunc, filterConfig)
 	if err != nil {
 		return fmt.Errorf("Unable to filter resources from state. Error: %v", err)
 	}
+	replacementCounts, err := filter.ApplyReplacements(result.Resources, filterConfig.Replacements)
+	if err != nil {
+		return fmt.Errorf("Unable to replace attribute values. Error: %v", err)
+	}
+	outputs, outputRenames, err := filter.FilterOutputs(oldState.Outputs, filterConfig.Outputs)
+	if err != nil {
+		return fmt.Errorf("Unable to filter outputs from state. Error: %v", err)
+	}
 
-	newState, err := pullTFState(newWorkspaceName)
+	if !opts.DryRun {
+		unlock, err := lockState(newStore)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	newStateBytes, err := newStore.Read()
 	if err != nil {
 		return tfdrerrors.ErrReadState{Err: err}
 	}
-	if newState != nil {
+	if newStateBytes != nil {
 		return tfdrerrors.ErrDestinationNotEmpty{}
 	}
 
-	newState = &models.State{
+	if opts.DryRun {
+		fmt.Fprintf(out, "Resources of %s that would be copied to %s:\n\n", origStore, newStore)
+		diff.Resources(oldState.Resources, result.Resources, result.Renames).Write(out)
+		writeReplacementCounts(filterConfig.Replacements, replacementCounts)
+		if len(oldState.Outputs) > 0 {
+			fmt.Fprintf(out, "\nOutputs that would be copied:\n\n")
+			diff.Outputs(oldState.Outputs, outputs, outputRenames).Write(out)
+		}
+		if len(result.Resources) == 0 {
+			return tfdrerrors.ErrNoResourcesMatched{}
+		}
+		return nil
+	}
+
+	err = backupState(oldStateBytes, origStore, opts)
+	if err != nil {
+		return err
+	}
+
+	newState := &models.State{
 		TerraformVersion: oldState.TerraformVersion,
 		Version:          oldState.Version,
-		Resources:        newResources,
+		Outputs:          outputs,
+		Resources:        result.Resources,
 		Serial:           1,
 	}
 
-	err = createTFStateVersion(newState, newWorkspaceName)
+	err = writeState(newStore, newState)
 	if err != nil {
 		return tfdrerrors.ErrUnableToCreateStateVersion{Err: err}
 	}
 
 	return nil
 }
+
+func writeReplacementCounts(replacements []models.Replacement, counts []int) {
+	if len(replacements) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\nReplacements:\n")
+	for i, replacement := range replacements {
+		fmt.Fprintf(out, "  %q -> %q: %d values changed\n", replacement.Find, replacement.Replace, counts[i])
+	}
+}
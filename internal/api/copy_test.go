@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net/http"
@@ -31,6 +32,7 @@ func (s *CopySuite) SetupTest() {
 }
 
 func (s *CopySuite) TearDownTest() {
+	os.RemoveAll(testBackupDir)
 	os.Unsetenv("TF_TEAM_TOKEN")
 	os.Unsetenv("TF_ORG_NAME")
 }
@@ -40,6 +42,8 @@ func (s *CopySuite) TestCopyTFState() {
 		origwks           *testutils.TfeTestWks
 		newwks            *testutils.TfeTestWks
 		filterFile        string
+		dryRun            bool
+		outputContains    []string
 		shouldErr         bool
 		errValidationFunc func(error) bool
 		errMessage        string
@@ -67,6 +71,7 @@ func (s *CopySuite) TestCopyTFState() {
 					s.Equal("", state.Lineage)
 					s.Equal(int64(1), state.Serial)
 					s.Equal(numFilters+len(testutils.GlobalResources), len(state.Resources))
+					s.Equal(testutils.NewStateOutputs(), state.Outputs)
 
 					resp, err := testutils.NewJSONResponse("test2", "state-versions", "https://state")
 					s.NoError(err)
@@ -76,6 +81,9 @@ func (s *CopySuite) TestCopyTFState() {
 			},
 			filterFile: "./testdata/filterConfig.json",
 			shouldErr:  false,
+			outputContains: []string{
+				"Backed up state of test1 to test-backups/team/test1/",
+			},
 			errMessage: "Test succesful copy state failed",
 		},
 		{
@@ -165,6 +173,72 @@ func (s *CopySuite) TestCopyTFState() {
 			},
 			errMessage: "Test copy error when state create error failed",
 		},
+		{
+			origwks: &testutils.TfeTestWks{
+				Name:         "test1",
+				Exists:       true,
+				CurrentState: testutils.NewState(),
+				CsvResponder: testutils.NewResponder("test", "state-versions", "https://state"),
+			},
+			newwks: &testutils.TfeTestWks{
+				Name:         "test2",
+				Exists:       true,
+				CsvResponder: httpmock.NewStringResponder(404, ""),
+			},
+			filterFile: "./testdata/filterConfig.json",
+			dryRun:     true,
+			shouldErr:  false,
+			outputContains: []string{
+				"  - module.test_module_0.type_0.orig_name_0\n",
+				"  > module.test_module_1.type_1.orig_name_1 -> module.test_module_1.type_1.new_name_1\n",
+				"  ~ module.test_module_2.type_2.orig_name_2 [attr1 attr2]\n",
+				"0 added, 8 removed, 1 renamed, 1 changed, 5 kept",
+				"Outputs that would be copied:\n\n    output.bucket_name\n    output.db_password\n    output.subnet_ids\n",
+			},
+			errMessage: "Test dry run copy state failed",
+		},
+		{
+			origwks: &testutils.TfeTestWks{
+				Name:         "test1",
+				Exists:       true,
+				CurrentState: testutils.NewState(),
+				CsvResponder: testutils.NewResponder("test", "state-versions", "https://state"),
+			},
+			newwks: &testutils.TfeTestWks{
+				Name:         "test2",
+				Exists:       true,
+				CsvResponder: httpmock.NewStringResponder(404, ""),
+			},
+			filterFile: "./testdata/replacementFilterConfig.json",
+			dryRun:     true,
+			shouldErr:  false,
+			outputContains: []string{
+				"  ~ module.test_module_1.type_1.orig_name_1 [attr1 attr2]\n",
+				"  ~ module.test_module_2.type_2.orig_name_2 [attr1 attr2]\n",
+				"Replacements:\n",
+				"  \"old_\" -> \"dr_\": 2 values changed\n",
+				"  \"^(new|old)_value_(\\\\d)$\" -> \"${1}_dr_value_$2\": 2 values changed\n",
+			},
+			errMessage: "Test dry run copy state with replacements failed",
+		},
+		{
+			origwks: &testutils.TfeTestWks{
+				Name:         "test1",
+				Exists:       true,
+				CurrentState: testutils.NewState(),
+				CsvResponder: testutils.NewResponder("test", "state-versions", "https://state"),
+			},
+			newwks: &testutils.TfeTestWks{
+				Name:         "test2",
+				Exists:       true,
+				CsvResponder: httpmock.NewStringResponder(404, ""),
+			},
+			filterFile:        "./testdata/noMatchFilterConfig.json",
+			dryRun:            true,
+			shouldErr:         true,
+			errValidationFunc: func(err error) bool { return errors.Is(err, tfdrerrors.ErrNoResourcesMatched{}) },
+			errMessage:        "Test dry run copy error when filter matches nothing failed",
+		},
 	}
 
 	for _, c := range cases {
@@ -175,7 +249,10 @@ func (s *CopySuite) TestCopyTFState() {
 		err = testutils.SetupWksMockHTTPResponses(c.newwks)
 		s.NoError(err, c.errMessage)
 
-		err = CopyTFState(c.origwks.Name, c.newwks.Name, c.filterFile)
+		var buf bytes.Buffer
+		out = &buf
+
+		err = CopyTFState(c.origwks.Name, c.newwks.Name, c.filterFile, Options{DryRun: c.dryRun, BackupDir: testBackupDir})
 
 		if c.shouldErr {
 			s.Error(err, c.errMessage)
@@ -185,6 +262,9 @@ func (s *CopySuite) TestCopyTFState() {
 		} else {
 			s.NoError(err, c.errMessage)
 		}
+		for _, o := range c.outputContains {
+			s.Contains(buf.String(), o, c.errMessage)
+		}
 		httpmock.DeactivateAndReset()
 	}
 }
@@ -3,13 +3,29 @@ package api
 import (
 	"fmt"
 
+	"github.com/mupuri/go-tfdr/internal/diff"
 	"github.com/mupuri/go-tfdr/internal/filter"
+	"github.com/mupuri/go-tfdr/internal/models"
 	"github.com/mupuri/go-tfdr/internal/tfdrerrors"
 )
 
-// DeleteTFStateResources &
-func DeleteTFStateResources(workspaceName string, filterConfigFileName string) error {
-	state, err := pullTFState(workspaceName)
+// DeleteTFStateResources removes the resources selected by the filter config from the state store at
+// address. Stores are addressed as described in NewStateStore.
+func DeleteTFStateResources(address string, filterConfigFileName string, opts Options) error {
+	store, err := NewStateStore(address)
+	if err != nil {
+		return err
+	}
+
+	if !opts.DryRun {
+		unlock, err := lockState(store)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	state, stateBytes, err := readState(store)
 	if err != nil {
 		return tfdrerrors.ErrReadState{Err: err}
 	}
@@ -17,16 +33,59 @@ func DeleteTFStateResources(workspaceName string, filterConfigFileName string) e
 		return tfdrerrors.ErrSourceIsEmpty{}
 	}
 
-	state.Resources, err = filter.StateFilter(state.Resources, filter.DeleteResourceFilterFunc, filterConfigFileName)
+	filterConfig, err := filter.ReadFilterConfigWithVars(filterConfigFileName, opts.Vars)
 	if err != nil {
 		return tfdrerrors.ErrUnableToFilter{Err: err}
 	}
+	result, err := filter.FilterStateResources(state.Resources, filter.DeleteResourceFilterFunc, filterConfig)
+	if err != nil {
+		return tfdrerrors.ErrUnableToFilter{Err: err}
+	}
+
+	if opts.DryRun {
+		fmt.Fprintf(out, "Changes to the resources of %s:\n\n", store)
+		diff.Resources(state.Resources, result.Resources, result.Renames).Write(out)
+		if !deletesInstances(state.Resources, result.Resources) {
+			return tfdrerrors.ErrNoResourcesMatched{}
+		}
+		return nil
+	}
+
+	err = backupState(stateBytes, store, opts)
+	if err != nil {
+		return err
+	}
+
+	state.Resources = result.Resources
 	state.Serial++
 
-	err = createTFStateVersion(state, workspaceName)
+	err = writeState(store, state)
 	if err != nil {
 		return fmt.Errorf("Unable to create new state version. Error: %v", err)
 	}
 	return nil
 
 }
+
+// deletesInstances returns true if a resource or instance of before is missing from after
+func deletesInstances(before []models.Resource, after []models.Resource) bool {
+	resources := make(map[string]bool, len(after))
+	instances := make(map[string]bool)
+	for _, resource := range after {
+		resources[resource.Address()] = true
+		for _, instance := range resource.Instances {
+			instances[resource.InstanceAddress(instance)] = true
+		}
+	}
+	for _, resource := range before {
+		if !resources[resource.Address()] {
+			return true
+		}
+		for _, instance := range resource.Instances {
+			if !instances[resource.InstanceAddress(instance)] {
+				return true
+			}
+		}
+	}
+	return false
+}
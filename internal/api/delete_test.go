@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"github.com/jarcoal/httpmock"
 	"github.com/mupuri/go-tfdr/internal/config"
 	"github.com/mupuri/go-tfdr/internal/logging"
+	"github.com/mupuri/go-tfdr/internal/models"
 	"github.com/mupuri/go-tfdr/internal/testutils"
 	"github.com/mupuri/go-tfdr/internal/tfdrerrors"
 	"github.com/stretchr/testify/suite"
@@ -31,14 +33,28 @@ func (s *DeleteSuite) SetupTest() {
 }
 
 func (s *DeleteSuite) TearDownTest() {
+	os.RemoveAll(testBackupDir)
 	os.Unsetenv("TF_TEAM_TOKEN")
 	os.Unsetenv("TF_ORG_NAME")
 }
 
 func (s *DeleteSuite) TestDeleteTFState() {
+	instanceState := testutils.NewState()
+	instanceState.Resources = append(instanceState.Resources, models.Resource{
+		Mode: "managed",
+		Type: "aws_instance",
+		Name: "web",
+		Instances: []models.Instance{
+			{IndexKey: 0, Attributes: map[string]interface{}{"id": "i-0", "ami": "ami-1"}},
+			{IndexKey: 1, Attributes: map[string]interface{}{"id": "i-1", "ami": "ami-2"}},
+		},
+	})
+
 	cases := []struct {
 		wks               *testutils.TfeTestWks
 		filterFile        string
+		dryRun            bool
+		outputContains    []string
 		shouldErr         bool
 		errValidationFunc func(error) bool
 		errMessage        string
@@ -69,6 +85,9 @@ func (s *DeleteSuite) TestDeleteTFState() {
 			},
 			filterFile: "./testdata/filterConfig.json",
 			shouldErr:  false,
+			outputContains: []string{
+				"Backed up state of test1 to test-backups/team/test1/",
+			},
 			errMessage: "Test succesful delete state resources failed",
 		},
 		{
@@ -90,7 +109,7 @@ func (s *DeleteSuite) TestDeleteTFState() {
 			filterFile: "",
 			shouldErr:  true,
 			errValidationFunc: func(err error) bool {
-				return errors.Is(err, tfdrerrors.ErrReadState{
+				return errors.Is(err, tfdrerrors.ErrUnableToLockState{
 					Err: tfdrerrors.ErrGetWorkspace{Err: tfe.ErrResourceNotFound},
 				})
 			},
@@ -125,6 +144,71 @@ func (s *DeleteSuite) TestDeleteTFState() {
 			},
 			errMessage: "Test delete error when state create error failed",
 		},
+		{
+			wks: &testutils.TfeTestWks{
+				Name:         "test1",
+				Exists:       true,
+				CurrentState: testutils.NewState(),
+				CsvResponder: testutils.NewResponder("test", "state-versions", "https://state"),
+			},
+			filterFile: "./testdata/filterConfig.json",
+			dryRun:     true,
+			shouldErr:  false,
+			outputContains: []string{
+				"  - module.test_module_1.type_1.orig_name_1\n",
+				"  - module.test_global_module_0.aws_cloudfront_distribution.global_orig_name_0\n",
+				"    module.test_module_0.type_0.orig_name_0\n",
+				"0 added, 7 removed, 0 renamed, 0 changed, 8 kept",
+			},
+			errMessage: "Test dry run delete state resources failed",
+		},
+		{
+			wks: &testutils.TfeTestWks{
+				Name:         "test1",
+				Exists:       true,
+				CurrentState: testutils.NewState(),
+				CsvResponder: testutils.NewResponder("test", "state-versions", "https://state"),
+			},
+			filterFile:        "./testdata/noMatchFilterConfig.json",
+			dryRun:            true,
+			shouldErr:         true,
+			errValidationFunc: func(err error) bool { return errors.Is(err, tfdrerrors.ErrNoResourcesMatched{}) },
+			errMessage:        "Test dry run delete error when filter matches nothing failed",
+		},
+		{
+			wks: &testutils.TfeTestWks{
+				Name:         "test1",
+				Exists:       true,
+				CurrentState: instanceState,
+				CsvResponder: testutils.NewResponder("test", "state-versions", "https://state"),
+			},
+			filterFile: "./testdata/instanceFilterConfig.json",
+			dryRun:     true,
+			shouldErr:  false,
+			outputContains: []string{
+				"  - aws_instance.web[0]\n",
+				"    aws_instance.web[1]\n",
+				"0 added, 1 removed, 0 renamed, 0 changed, 16 kept",
+			},
+			errMessage: "Test dry run delete of an instance selected by its index key failed",
+		},
+		{
+			wks: &testutils.TfeTestWks{
+				Name:         "test1",
+				Exists:       true,
+				CurrentState: instanceState,
+				CsvResponder: testutils.NewResponder("test", "state-versions", "https://state"),
+			},
+			filterFile: "./testdata/whereFilterConfig.json",
+			dryRun:     true,
+			shouldErr:  false,
+			outputContains: []string{
+				"  - aws_instance.web[1]\n",
+				"    aws_instance.web[0]\n",
+				"0 added, 1 removed, 0 renamed, 0 changed, 16 kept",
+			},
+			errMessage: "Test dry run delete of an instance selected by where failed",
+		},
 	}
 
 	for _, c := range cases {
@@ -133,7 +217,10 @@ func (s *DeleteSuite) TestDeleteTFState() {
 		err := testutils.SetupWksMockHTTPResponses(c.wks)
 		s.NoError(err, c.errMessage)
 
-		err = DeleteTFStateResources(c.wks.Name, c.filterFile)
+		var buf bytes.Buffer
+		out = &buf
+
+		err = DeleteTFStateResources(c.wks.Name, c.filterFile, Options{DryRun: c.dryRun, BackupDir: testBackupDir})
 
 		if c.shouldErr {
 			s.Error(err, c.errMessage)
@@ -143,6 +230,9 @@ func (s *DeleteSuite) TestDeleteTFState() {
 		} else {
 			s.NoError(err, c.errMessage)
 		}
+		for _, o := range c.outputContains {
+			s.Contains(buf.String(), o, c.errMessage)
+		}
 		httpmock.DeactivateAndReset()
 	}
 }
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fileStore is a local terraform.tfstate file
+type fileStore struct {
+	path    string
+	address string
+}
+
+func (s *fileStore) lockPath() string {
+	return s.path + ".tfdr.lock"
+}
+
+func (s *fileStore) Read() ([]byte, error) {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return readStateFile(s.path)
+}
+
+func (s *fileStore) Write(stateBytes []byte) error {
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, stateBytes, 0600); err != nil {
+		return fmt.Errorf("Unable to write state file %s. Error: %v", tmp, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("Unable to write state file %s. Error: %v", s.path, err)
+	}
+	return nil
+}
+
+func (s *fileStore) Lock() error {
+	f, err := os.OpenFile(s.lockPath(), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("State file %s is locked by %s", s.path, s.lockPath())
+		}
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "locked by tfdr process %d\n", os.Getpid())
+	return err
+}
+
+func (s *fileStore) Unlock() error {
+	return os.Remove(s.lockPath())
+}
+
+func (s *fileStore) BackupPath() string {
+	name := filepath.Base(s.path)
+	return filepath.Join("local", strings.TrimSuffix(name, filepath.Ext(name)))
+}
+
+func (s *fileStore) String() string {
+	return s.address
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"github.com/mupuri/go-tfdr/internal/filter"
+	"github.com/mupuri/go-tfdr/internal/tfdrerrors"
+)
+
+// LintFilter checks the filter config, with the values of vars, against the current state of the
+// state store at address. Stores are addressed as described in NewStateStore.
+func LintFilter(address string, filterConfigFileName string, vars map[string]string) (*filter.Report, error) {
+	filterConfig, err := filter.ReadFilterConfigWithVars(filterConfigFileName, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := NewStateStore(address)
+	if err != nil {
+		return nil, err
+	}
+	state, _, err := readState(store)
+	if err != nil {
+		return nil, tfdrerrors.ErrReadState{Err: err}
+	}
+	if state == nil {
+		return nil, tfdrerrors.ErrSourceIsEmpty{}
+	}
+
+	return filter.Lint(state.Resources, filterConfig), nil
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mupuri/go-tfdr/internal/config"
+	"github.com/mupuri/go-tfdr/internal/logging"
+	"github.com/mupuri/go-tfdr/internal/testutils"
+	"github.com/mupuri/go-tfdr/internal/tfdrerrors"
+	"github.com/stretchr/testify/suite"
+)
+
+type LintSuite struct {
+	suite.Suite
+}
+
+func (s *LintSuite) SetupTest() {
+	os.Setenv("TF_TEAM_TOKEN", "test")
+	os.Setenv("TF_ORG_NAME", "team")
+	config.InitConfig("")
+	logging.InitLogger()
+	os.MkdirAll(testStateDir, 0755)
+}
+
+func (s *LintSuite) TearDownTest() {
+	os.RemoveAll(testStateDir)
+	os.Unsetenv("TF_TEAM_TOKEN")
+	os.Unsetenv("TF_ORG_NAME")
+}
+
+func (s *LintSuite) TestLintFilter() {
+	path := filepath.Join(testStateDir, "terraform.tfstate")
+	s.NoError(writeTestState(path, testutils.NewState()))
+
+	report, err := LintFilter("file://"+path, "./testdata/filterConfig.json", nil)
+	s.NoError(err)
+	s.Equal(0, report.Errors)
+	s.Equal(testutils.DefaultNumResources(), report.Resources)
+	s.Equal(len(testutils.GlobalResources)+2, report.Selected)
+
+	report, err = LintFilter("file://"+path, "./testdata/noMatchFilterConfig.json", nil)
+	s.NoError(err)
+	s.Equal(1, report.Errors)
+}
+
+func (s *LintSuite) TestLintFilterVars() {
+	path := filepath.Join(testStateDir, "terraform.tfstate")
+	s.NoError(writeTestState(path, testutils.NewState()))
+
+	report, err := LintFilter("file://"+path, "./testdata/varFilterConfig.json", map[string]string{"module": "test_module_1"})
+	s.NoError(err)
+	s.Equal(0, report.Errors)
+	s.Equal(1, report.Selected)
+
+	_, err = LintFilter("file://"+path, "./testdata/varFilterConfig.json", nil)
+	s.Error(err)
+	s.Contains(err.Error(), `Variable "module" is not defined`)
+}
+
+func (s *LintSuite) TestLintFilterErrors() {
+	path := filepath.Join(testStateDir, "terraform.tfstate")
+
+	_, err := LintFilter("file://"+path, "./testdata/filterConfig.json", nil)
+	s.Equal(tfdrerrors.ErrSourceIsEmpty{}, err)
+
+	_, err = LintFilter("file://"+path, "./testdata/not-found.json", nil)
+	s.IsType(tfdrerrors.ErrReadFilterFile{}, err)
+}
+
+func TestLintSuite(t *testing.T) {
+	suite.Run(t, new(LintSuite))
+}
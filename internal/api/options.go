@@ -0,0 +1,68 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mupuri/go-tfdr/internal/backup"
+	"github.com/mupuri/go-tfdr/internal/config"
+	"github.com/mupuri/go-tfdr/internal/tfdrerrors"
+	"github.com/sirupsen/logrus"
+)
+
+// out is where command output such as dry run diffs is written
+var out io.Writer = os.Stdout
+
+// in is where answers to confirmation prompts are read from
+var in io.Reader = os.Stdin
+
+// Options &
+type Options struct {
+	// DryRun prints the changes that would be made to the state instead of uploading it
+	DryRun bool
+	// BackupDir overrides the default backup directory ($HOME/.tfdr/backups)
+	BackupDir string
+	// AutoApprove skips confirmation prompts
+	AutoApprove bool
+	// NoBackup skips backing up the state before it is changed
+	NoBackup bool
+	// SourceConfig and DestinationConfig set the Terraform Cloud host, organization and token of the
+	// workspaces CopyTFState reads from and writes to. Nil uses the global configuration
+	SourceConfig      *config.Configuration
+	DestinationConfig *config.Configuration
+	// Vars are the values of the ${var.<name>} references in filter config files
+	Vars map[string]string
+}
+
+func configOrDefault(c *config.Configuration) *config.Configuration {
+	if c == nil {
+		return config.GetConfig()
+	}
+	return c
+}
+
+// backupState writes the json of a state read from store to the backup directory
+func backupState(stateBytes []byte, store StateStore, opts Options) error {
+	if opts.NoBackup {
+		return nil
+	}
+	backupFile, err := backup.Write(opts.BackupDir, store.BackupPath(), stateBytes)
+	if err != nil {
+		return tfdrerrors.ErrUnableToBackupState{Err: err}
+	}
+	fmt.Fprintf(out, "Backed up state of %s to %s\n", store, backupFile)
+	return nil
+}
+
+// lockState locks store and returns a func that unlocks it
+func lockState(store StateStore) (func(), error) {
+	if err := store.Lock(); err != nil {
+		return nil, tfdrerrors.ErrUnableToLockState{Err: err}
+	}
+	return func() {
+		if err := store.Unlock(); err != nil {
+			logrus.Warnf("Unable to unlock %s. Error: %v", store, err)
+		}
+	}, nil
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/mupuri/go-tfdr/internal/diff"
+	"github.com/mupuri/go-tfdr/internal/models"
+	"github.com/mupuri/go-tfdr/internal/tfdrerrors"
+	"github.com/sirupsen/logrus"
+)
+
+// RestoreTFState re-uploads a previous state version of a Terraform Cloud workspace, or a local backup file,
+// as the current state of the state store at address. Stores are addressed as described in NewStateStore.
+func RestoreTFState(address string, stateVersionID string, backupFileName string, opts Options) error {
+	store, err := NewStateStore(address)
+	if err != nil {
+		return err
+	}
+	tfc, ok := store.(*tfcStore)
+	if !ok && stateVersionID != "" {
+		return fmt.Errorf("State versions can only be restored to Terraform Cloud workspaces")
+	}
+
+	var restoredBytes []byte
+	var source string
+	if stateVersionID != "" {
+		source = fmt.Sprintf("state version %s", stateVersionID)
+		restoredBytes, err = tfc.ReadVersion(stateVersionID)
+	} else {
+		source = backupFileName
+		restoredBytes, err = readStateFile(backupFileName)
+	}
+	if err != nil {
+		return tfdrerrors.ErrReadState{Err: err}
+	}
+	// the restored state is only decoded for the preview. It is uploaded as it was read, with the
+	// serial and lineage of the workspace.
+	restored, err := decodeState(restoredBytes)
+	if err != nil {
+		return tfdrerrors.ErrReadState{Err: err}
+	}
+
+	if !opts.DryRun {
+		unlock, err := lockState(store)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	current, currentBytes, err := readState(store)
+	if err != nil {
+		return tfdrerrors.ErrReadState{Err: err}
+	}
+
+	currentResources := make([]models.Resource, 0)
+	if current != nil {
+		currentResources = current.Resources
+		if current.Lineage != "" && current.Lineage != restored.Lineage {
+			logrus.Warnf("Lineage of %s (%s) does not match workspace lineage (%s). Keeping workspace lineage.", source, restored.Lineage, current.Lineage)
+			restored.Lineage = current.Lineage
+		}
+		restored.Serial = current.Serial + 1
+	}
+
+	fmt.Fprintf(out, "Changes to the resources of %s when restored to %s:\n\n", store, source)
+	diff.Resources(currentResources, restored.Resources, nil).Write(out)
+
+	if opts.DryRun {
+		return nil
+	}
+	if !opts.AutoApprove && !confirm(fmt.Sprintf("\nRestore %s to %s? [y/N] ", store, source)) {
+		return tfdrerrors.ErrRestoreCancelled{}
+	}
+
+	if current != nil {
+		err = backupState(currentBytes, store, opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	if current != nil {
+		restoredBytes, err = setSerialAndLineage(restoredBytes, restored.Serial, restored.Lineage)
+		if err != nil {
+			return tfdrerrors.ErrUnableToCreateStateVersion{Err: err}
+		}
+	}
+	err = store.Write(restoredBytes)
+	if err != nil {
+		return tfdrerrors.ErrUnableToCreateStateVersion{Err: err}
+	}
+	fmt.Fprintf(out, "Restored %s to %s with serial %d\n", store, source, restored.Serial)
+	return nil
+}
+
+// setSerialAndLineage sets the serial and lineage of the state json and leaves everything else as it is
+func setSerialAndLineage(stateBytes []byte, serial int64, lineage string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(stateBytes))
+	decoder.UseNumber()
+	var state map[string]interface{}
+	if err := decoder.Decode(&state); err != nil {
+		return nil, fmt.Errorf("Cannot unmarshal state json. Err: %v", err)
+	}
+	state["serial"] = serial
+	state["lineage"] = lineage
+
+	stateBytes, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("Unable to marshal state object. Error: %v", err)
+	}
+	return stateBytes, nil
+}
+
+func readStateFile(fileName string) ([]byte, error) {
+	stateBytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read file. Err: %v", err)
+	}
+	return stateBytes, nil
+}
+
+func confirm(prompt string) bool {
+	fmt.Fprint(out, prompt)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
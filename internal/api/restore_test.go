@@ -0,0 +1,244 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/mupuri/go-tfdr/internal/config"
+	"github.com/mupuri/go-tfdr/internal/logging"
+	"github.com/mupuri/go-tfdr/internal/models"
+	"github.com/mupuri/go-tfdr/internal/testutils"
+	"github.com/mupuri/go-tfdr/internal/tfdrerrors"
+	"github.com/stretchr/testify/suite"
+)
+
+type RestoreSuite struct {
+	suite.Suite
+}
+
+func (s *RestoreSuite) SetupSuite() {}
+
+func (s *RestoreSuite) SetupTest() {
+	os.Setenv("TF_TEAM_TOKEN", "test")
+	os.Setenv("TF_ORG_NAME", "team")
+	config.InitConfig("")
+	logging.InitLogger()
+}
+
+func (s *RestoreSuite) TearDownTest() {
+	os.RemoveAll(testBackupDir)
+	os.Unsetenv("TF_TEAM_TOKEN")
+	os.Unsetenv("TF_ORG_NAME")
+	in = os.Stdin
+}
+
+func (s *RestoreSuite) restoredStateResponder(numResources int) httpmock.Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		state, err := testutils.DecodeStateFromBody(req)
+		s.NoError(err)
+
+		s.Equal(testutils.DefaultLineage, state.Lineage)
+		s.Equal(testutils.DefaultSerial+1, state.Serial)
+		s.Equal(numResources, len(state.Resources))
+
+		return testutils.NewJSONResponse("test", "state-versions", "https://state")
+	}
+}
+
+func (s *RestoreSuite) TestRestoreTFState() {
+	oldState := testutils.NewState()
+	oldState.Resources = oldState.Resources[:3]
+	oldStateJSON, err := json.Marshal(oldState)
+	s.NoError(err)
+	stateVersionList := `{"data":[{"id":"sv-new","type":"state-versions"}],"meta":{"pagination":{"current-page":1,"next-page":2,"total-pages":2}}}`
+	stateVersionListPage2 := `{"data":[{"id":"sv-old","type":"state-versions"}],"meta":{"pagination":{"current-page":2,"total-pages":2}}}`
+
+	cases := []struct {
+		wks               *testutils.TfeTestWks
+		stateVersionID    string
+		backupFile        string
+		opts              Options
+		input             string
+		shouldErr         bool
+		errValidationFunc func(error) bool
+		outputContains    []string
+		errMessage        string
+	}{
+		{
+			wks: &testutils.TfeTestWks{
+				Name:            "test1",
+				Exists:          true,
+				CurrentState:    testutils.NewState(),
+				CsvResponder:    testutils.NewResponder("test", "state-versions", "https://state"),
+				SvPostResponder: s.restoredStateResponder(2),
+			},
+			backupFile: "./testdata/backupState.json",
+			opts:       Options{AutoApprove: true},
+			shouldErr:  false,
+			outputContains: []string{
+				"  + module.test_module_backup.type_backup.backup_name\n",
+				"  - module.test_module_1.type_1.orig_name_1\n",
+				"1 added, 14 removed, 0 renamed, 0 changed, 1 kept",
+				"Backed up state of test1 to test-backups/team/test1/",
+				"Restored test1 to ./testdata/backupState.json with serial 2",
+			},
+			errMessage: "Test succesful restore from backup file failed",
+		},
+		{
+			wks: &testutils.TfeTestWks{
+				Name:            "test1",
+				Exists:          true,
+				CurrentState:    testutils.NewState(),
+				CsvResponder:    testutils.NewResponder("test", "state-versions", "https://state"),
+				SvPostResponder: s.restoredStateResponder(3),
+			},
+			stateVersionID: "sv-old",
+			opts:           Options{},
+			input:          "y\n",
+			shouldErr:      false,
+			outputContains: []string{
+				"0 added, 12 removed, 0 renamed, 0 changed, 3 kept",
+				"Restore test1 to state version sv-old? [y/N] ",
+				"Restored test1 to state version sv-old with serial 2",
+			},
+			errMessage: "Test succesful restore from state version failed",
+		},
+		{
+			wks: &testutils.TfeTestWks{
+				Name:         "test1",
+				Exists:       true,
+				CurrentState: testutils.NewState(),
+				CsvResponder: testutils.NewResponder("test", "state-versions", "https://state"),
+			},
+			stateVersionID:    "sv-old",
+			opts:              Options{},
+			input:             "n\n",
+			shouldErr:         true,
+			errValidationFunc: func(err error) bool { return errors.Is(err, tfdrerrors.ErrRestoreCancelled{}) },
+			errMessage:        "Test restore cancelled when not confirmed failed",
+		},
+		{
+			wks: &testutils.TfeTestWks{
+				Name:         "test1",
+				Exists:       true,
+				CurrentState: testutils.NewState(),
+				CsvResponder: testutils.NewResponder("test", "state-versions", "https://state"),
+			},
+			stateVersionID: "sv-old",
+			opts:           Options{DryRun: true},
+			shouldErr:      false,
+			outputContains: []string{
+				"Changes to the resources of test1 when restored to state version sv-old:",
+			},
+			errMessage: "Test dry run restore failed",
+		},
+		{
+			wks: &testutils.TfeTestWks{
+				Name:         "test1",
+				Exists:       true,
+				CurrentState: testutils.NewState(),
+				CsvResponder: testutils.NewResponder("test", "state-versions", "https://state"),
+			},
+			stateVersionID: "sv-other",
+			opts:           Options{AutoApprove: true},
+			shouldErr:      true,
+			errValidationFunc: func(err error) bool {
+				return errors.Is(err, tfdrerrors.ErrReadState{Err: tfdrerrors.ErrStateVersionNotInWorkspace{StateVersionID: "sv-other", Workspace: "team/test1"}})
+			},
+			errMessage: "Test restore of a state version of another workspace failed",
+		},
+		{
+			wks: &testutils.TfeTestWks{
+				Name:         "test1",
+				Exists:       true,
+				CurrentState: testutils.NewState(),
+				CsvResponder: testutils.NewResponder("test", "state-versions", "https://state"),
+			},
+			backupFile: "./testdata/not-found-state.json",
+			opts:       Options{AutoApprove: true},
+			shouldErr:  true,
+			errValidationFunc: func(err error) bool {
+				return strings.Contains(err.Error(), "Unable to read origin state. Error: Unable to read file.")
+			},
+			errMessage: "Test restore error when backup file not found failed",
+		},
+	}
+
+	for _, c := range cases {
+		httpmock.ActivateNonDefault(httpClient)
+		httpmock.RegisterResponder("GET", "https://app.terraform.io/api/v2/ping", httpmock.NewStringResponder(204, ""))
+		httpmock.RegisterResponderWithQuery("GET", "https://app.terraform.io/api/v2/state-versions", "filter[organization][name]=team&filter[workspace][name]=test1", httpmock.NewStringResponder(200, stateVersionList))
+		httpmock.RegisterResponderWithQuery("GET", "https://app.terraform.io/api/v2/state-versions", "filter[organization][name]=team&filter[workspace][name]=test1&page[number]=2", httpmock.NewStringResponder(200, stateVersionListPage2))
+		httpmock.RegisterResponder("GET", "https://app.terraform.io/api/v2/state-versions/sv-old", testutils.NewResponder("sv-old", "state-versions", "https://old-state"))
+		httpmock.RegisterResponder("GET", "https://old-state", httpmock.NewStringResponder(200, string(oldStateJSON)))
+		err := testutils.SetupWksMockHTTPResponses(c.wks)
+		s.NoError(err, c.errMessage)
+
+		var buf bytes.Buffer
+		out = &buf
+		in = strings.NewReader(c.input)
+		c.opts.BackupDir = testBackupDir
+
+		err = RestoreTFState(c.wks.Name, c.stateVersionID, c.backupFile, c.opts)
+
+		if c.shouldErr {
+			s.Error(err, c.errMessage)
+			if c.errValidationFunc != nil && err != nil {
+				s.True(c.errValidationFunc(err), fmt.Sprintf("%v. Invalid error returned: %v", c.errMessage, err))
+			}
+		} else {
+			s.NoError(err, c.errMessage)
+		}
+		for _, o := range c.outputContains {
+			s.Contains(buf.String(), o, c.errMessage)
+		}
+		httpmock.DeactivateAndReset()
+	}
+}
+
+func (s *RestoreSuite) TestRestoreTFStateKeepsState() {
+	os.MkdirAll(testStateDir, 0755)
+	defer os.RemoveAll(testStateDir)
+	path := filepath.Join(testStateDir, "terraform.tfstate")
+	s.NoError(writeTestState(path, testutils.NewState()))
+	out = &bytes.Buffer{}
+
+	err := RestoreTFState("file://"+path, "", "./testdata/taintedBackupState.json", Options{AutoApprove: true, NoBackup: true})
+	s.NoError(err)
+
+	stateBytes, err := ioutil.ReadFile(path)
+	s.NoError(err)
+	var state map[string]interface{}
+	s.NoError(json.Unmarshal(stateBytes, &state))
+	s.Equal(float64(testutils.DefaultSerial+1), state["serial"])
+	s.Equal(testutils.DefaultLineage, state["lineage"])
+	s.Equal(float64(12345678901234567), state["check_results"].(map[string]interface{})["count"])
+	resource := state["resources"].([]interface{})[0].(map[string]interface{})
+	instance := resource["instances"].([]interface{})[0].(map[string]interface{})
+	s.Equal("tainted", instance["status"])
+	s.Equal(true, instance["create_before_destroy"])
+	s.Equal([]interface{}{map[string]interface{}{"type": "get_attr", "value": "password"}}, instance["sensitive_attributes"])
+	s.Contains(string(stateBytes), "12345678901234567", "Test restore keeps large numbers failed")
+}
+
+func (s *RestoreSuite) TestReadStateFile() {
+	stateBytes, err := readStateFile("./testdata/backupState.json")
+	s.NoError(err)
+	state, err := decodeState(stateBytes)
+	s.NoError(err)
+	s.Equal("old", state.Lineage)
+	s.Equal([]models.Instance{}, state.Resources[1].Instances)
+}
+
+func TestRestoreSuite(t *testing.T) {
+	suite.Run(t, new(RestoreSuite))
+}
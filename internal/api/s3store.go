@@ -0,0 +1,257 @@
+package api
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const s3Scheme = "s3://"
+
+// s3LockInfo is the lock info terraform's S3 backend stores in the Info attribute of the lock table item
+type s3LockInfo struct {
+	ID        string
+	Operation string
+	Info      string
+	Who       string
+	Version   string
+	Created   time.Time
+	Path      string
+}
+
+// s3Store is a state file in an S3 compatible bucket. Like terraform's S3 backend, it locks the state
+// with an item in a DynamoDB table whose hash key is LockID, and keeps the md5 digest of the state in
+// the <bucket>/<key>-md5 item of the same table.
+type s3Store struct {
+	bucket    string
+	key       string
+	lockTable string
+	address   string
+
+	s3Client     *s3.S3
+	dynamoClient *dynamodb.DynamoDB
+	lockInfo     string
+}
+
+// newS3Store returns the store at s3://<bucket>/<key>. The query string can set region, profile, endpoint,
+// force_path_style, dynamodb_table and dynamodb_endpoint.
+func newS3Store(address string) (*s3Store, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid state address %q. Err: %v", address, err)
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || key == "" {
+		return nil, fmt.Errorf("Invalid state address %q. Expected s3://<bucket>/<key>", address)
+	}
+
+	q := u.Query()
+	awsConfig := aws.NewConfig().WithHTTPClient(httpClient)
+	if region := q.Get("region"); region != "" {
+		awsConfig = awsConfig.WithRegion(region)
+	}
+
+	// endpoints are set per client so a custom S3 endpoint is not used for DynamoDB
+	s3Config := aws.NewConfig()
+	if endpoint := q.Get("endpoint"); endpoint != "" {
+		s3Config = s3Config.WithEndpoint(endpoint)
+	}
+	if pathStyle := q.Get("force_path_style"); pathStyle != "" {
+		forcePathStyle, err := strconv.ParseBool(pathStyle)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid state address %q. force_path_style must be true or false", address)
+		}
+		s3Config = s3Config.WithS3ForcePathStyle(forcePathStyle)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *awsConfig,
+		Profile:           q.Get("profile"),
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create aws session. Err: %v", err)
+	}
+
+	dynamoConfig := aws.NewConfig()
+	if endpoint := q.Get("dynamodb_endpoint"); endpoint != "" {
+		dynamoConfig = dynamoConfig.WithEndpoint(endpoint)
+	}
+
+	return &s3Store{
+		bucket:       u.Host,
+		key:          key,
+		lockTable:    q.Get("dynamodb_table"),
+		address:      address,
+		s3Client:     s3.New(sess, s3Config),
+		dynamoClient: dynamodb.New(sess, dynamoConfig),
+	}, nil
+}
+
+func (s *s3Store) path() string {
+	return s.bucket + "/" + s.key
+}
+
+func (s *s3Store) digestID() string {
+	return s.path() + "-md5"
+}
+
+func (s *s3Store) Read() ([]byte, error) {
+	output, err := s.s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Unable to get s3 object %s. Err: %v", s.path(), err)
+	}
+	defer output.Body.Close()
+
+	stateBytes, err := ioutil.ReadAll(output.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read s3 object %s. Err: %v", s.path(), err)
+	}
+
+	if err := s.checkDigest(stateBytes); err != nil {
+		return nil, err
+	}
+	return stateBytes, nil
+}
+
+func (s *s3Store) Write(stateBytes []byte) error {
+	_, err := s.s3Client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(s.key),
+		Body:        bytes.NewReader(stateBytes),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to put s3 object %s. Err: %v", s.path(), err)
+	}
+
+	return s.putDigest(stateBytes)
+}
+
+func (s *s3Store) Lock() error {
+	if s.lockTable == "" {
+		return nil
+	}
+
+	who, _ := os.Hostname()
+	info, err := json.Marshal(s3LockInfo{
+		ID:        fmt.Sprintf("tfdr-%d-%d", os.Getpid(), time.Now().UnixNano()),
+		Operation: "tfdr",
+		Who:       who,
+		Created:   time.Now().UTC(),
+		Path:      s.path(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.dynamoClient.PutItem(&dynamodb.PutItemInput{
+		TableName: aws.String(s.lockTable),
+		Item: map[string]*dynamodb.AttributeValue{
+			"LockID": {S: aws.String(s.path())},
+			"Info":   {S: aws.String(string(info))},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(LockID)"),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return fmt.Errorf("State %s is locked in table %s", s.path(), s.lockTable)
+		}
+		return fmt.Errorf("Unable to lock state %s. Err: %v", s.path(), err)
+	}
+	s.lockInfo = string(info)
+	return nil
+}
+
+func (s *s3Store) Unlock() error {
+	if s.lockTable == "" || s.lockInfo == "" {
+		return nil
+	}
+
+	_, err := s.dynamoClient.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: aws.String(s.lockTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"LockID": {S: aws.String(s.path())},
+		},
+		ConditionExpression:       aws.String("Info = :info"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":info": {S: aws.String(s.lockInfo)}},
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to unlock state %s. Err: %v", s.path(), err)
+	}
+	s.lockInfo = ""
+	return nil
+}
+
+func (s *s3Store) checkDigest(stateBytes []byte) error {
+	if s.lockTable == "" {
+		return nil
+	}
+
+	output, err := s.dynamoClient.GetItem(&dynamodb.GetItemInput{
+		TableName:      aws.String(s.lockTable),
+		Key:            map[string]*dynamodb.AttributeValue{"LockID": {S: aws.String(s.digestID())}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to get state digest %s. Err: %v", s.digestID(), err)
+	}
+	digest, ok := output.Item["Digest"]
+	if !ok || digest.S == nil {
+		return nil
+	}
+
+	sum := md5.Sum(stateBytes)
+	if hex.EncodeToString(sum[:]) != *digest.S {
+		return fmt.Errorf("State %s does not match the digest stored in table %s", s.path(), s.lockTable)
+	}
+	return nil
+}
+
+func (s *s3Store) putDigest(stateBytes []byte) error {
+	if s.lockTable == "" {
+		return nil
+	}
+
+	sum := md5.Sum(stateBytes)
+	_, err := s.dynamoClient.PutItem(&dynamodb.PutItemInput{
+		TableName: aws.String(s.lockTable),
+		Item: map[string]*dynamodb.AttributeValue{
+			"LockID": {S: aws.String(s.digestID())},
+			"Digest": {S: aws.String(hex.EncodeToString(sum[:]))},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to update state digest %s. Err: %v", s.digestID(), err)
+	}
+	return nil
+}
+
+func (s *s3Store) BackupPath() string {
+	return filepath.Join("s3", s.bucket, strings.TrimSuffix(s.key, filepath.Ext(s.key)))
+}
+
+func (s *s3Store) String() string {
+	return s3Scheme + s.path()
+}
@@ -0,0 +1,232 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mupuri/go-tfdr/internal/testutils"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeS3 is a minimal stand-in for the path style S3 object API and the DynamoDB item API. The APIs
+// are served by separate handlers so requests sent to the wrong endpoint fail.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	items   map[string]map[string]map[string]string
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{
+		objects: make(map[string][]byte),
+		items:   make(map[string]map[string]map[string]string),
+	}
+}
+
+func (f *fakeS3) serveS3(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if req.Header.Get("X-Amz-Target") != "" {
+		w.WriteHeader(400)
+		fmt.Fprint(w, "<Error><Code>InvalidRequest</Code><Message>DynamoDB request sent to S3</Message></Error>")
+		return
+	}
+
+	switch req.Method {
+	case "GET":
+		body, ok := f.objects[req.URL.Path]
+		if !ok {
+			w.WriteHeader(404)
+			fmt.Fprint(w, "<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>")
+			return
+		}
+		w.Write(body)
+	case "PUT":
+		body, _ := ioutil.ReadAll(req.Body)
+		f.objects[req.URL.Path] = body
+	default:
+		w.WriteHeader(405)
+	}
+}
+
+type fakeDynamoRequest struct {
+	TableName                 string
+	Item                      map[string]map[string]string
+	Key                       map[string]map[string]string
+	ConditionExpression       string
+	ExpressionAttributeValues map[string]map[string]string
+}
+
+func (f *fakeS3) serveDynamoDB(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	operation := strings.TrimPrefix(req.Header.Get("X-Amz-Target"), "DynamoDB_20120810.")
+	var input fakeDynamoRequest
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		w.WriteHeader(400)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+
+	conditionFailed := func() {
+		w.WriteHeader(400)
+		fmt.Fprint(w, `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`)
+	}
+
+	switch operation {
+	case "PutItem":
+		id := input.Item["LockID"]["S"]
+		if _, ok := f.items[id]; ok && input.ConditionExpression == "attribute_not_exists(LockID)" {
+			conditionFailed()
+			return
+		}
+		f.items[id] = input.Item
+		fmt.Fprint(w, "{}")
+	case "GetItem":
+		item, ok := f.items[input.Key["LockID"]["S"]]
+		if !ok {
+			fmt.Fprint(w, "{}")
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"Item": item})
+	case "DeleteItem":
+		id := input.Key["LockID"]["S"]
+		item, ok := f.items[id]
+		if !ok || item["Info"]["S"] != input.ExpressionAttributeValues[":info"]["S"] {
+			conditionFailed()
+			return
+		}
+		delete(f.items, id)
+		fmt.Fprint(w, "{}")
+	default:
+		w.WriteHeader(400)
+	}
+}
+
+type S3StoreSuite struct {
+	suite.Suite
+	fake         *fakeS3
+	server       *httptest.Server
+	dynamoServer *httptest.Server
+}
+
+func (s *S3StoreSuite) SetupTest() {
+	os.Setenv("AWS_ACCESS_KEY_ID", "test")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	os.MkdirAll(testStateDir, 0755)
+	s.fake = newFakeS3()
+	s.server = httptest.NewServer(http.HandlerFunc(s.fake.serveS3))
+	s.dynamoServer = httptest.NewServer(http.HandlerFunc(s.fake.serveDynamoDB))
+}
+
+func (s *S3StoreSuite) TearDownTest() {
+	s.server.Close()
+	s.dynamoServer.Close()
+	os.RemoveAll(testStateDir)
+	os.RemoveAll(testBackupDir)
+	os.Unsetenv("AWS_ACCESS_KEY_ID")
+	os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+}
+
+func (s *S3StoreSuite) address(key string) string {
+	return fmt.Sprintf("s3://bucket/%s?region=us-east-1&endpoint=%s&force_path_style=true&dynamodb_table=locks&dynamodb_endpoint=%s", key, s.server.URL, s.dynamoServer.URL)
+}
+
+func (s *S3StoreSuite) TestS3StoreEndpoints() {
+	store, err := newS3Store("s3://bucket/terraform.tfstate?region=us-east-1&endpoint=http://minio:9000&dynamodb_table=locks")
+	s.NoError(err)
+	s.Equal("http://minio:9000", store.s3Client.Endpoint)
+	s.Equal("https://dynamodb.us-east-1.amazonaws.com", store.dynamoClient.Endpoint, "Test s3 endpoint is not used for dynamodb failed")
+
+	store, err = newS3Store("s3://bucket/terraform.tfstate?region=us-east-1&endpoint=http://minio:9000&dynamodb_table=locks&dynamodb_endpoint=http://dynamodb:8000")
+	s.NoError(err)
+	s.Equal("http://minio:9000", store.s3Client.Endpoint)
+	s.Equal("http://dynamodb:8000", store.dynamoClient.Endpoint)
+}
+
+func (s *S3StoreSuite) TestNewS3Store() {
+	cases := []struct {
+		address    string
+		shouldErr  bool
+		errMessage string
+	}{
+		{"s3://bucket/env/terraform.tfstate?region=us-east-1", false, "Test s3 address with key prefix failed"},
+		{"s3://bucket", true, "Test s3 address without key failed"},
+		{"s3:///terraform.tfstate", true, "Test s3 address without bucket failed"},
+		{"s3://bucket/terraform.tfstate?force_path_style=maybe", true, "Test s3 address with invalid force_path_style failed"},
+	}
+
+	for _, c := range cases {
+		store, err := NewStateStore(c.address)
+		if c.shouldErr {
+			s.Error(err, c.errMessage)
+			continue
+		}
+		s.NoError(err, c.errMessage)
+		s.Equal("s3://bucket/env/terraform.tfstate", store.String(), c.errMessage)
+		s.Equal(filepath.Join("s3", "bucket", "env", "terraform"), store.BackupPath(), c.errMessage)
+	}
+}
+
+func (s *S3StoreSuite) TestS3Store() {
+	store, err := newS3Store(s.address("terraform.tfstate"))
+	s.NoError(err)
+
+	state, _, err := readState(store)
+	s.NoError(err)
+	s.Nil(state)
+
+	s.NoError(store.Lock())
+	other, err := newS3Store(s.address("terraform.tfstate"))
+	s.NoError(err)
+	s.EqualError(other.Lock(), "State bucket/terraform.tfstate is locked in table locks")
+
+	s.NoError(writeState(store, testutils.NewState()))
+	s.NoError(store.Unlock())
+	s.NotContains(s.fake.items, "bucket/terraform.tfstate")
+	s.Contains(s.fake.items, "bucket/terraform.tfstate-md5")
+
+	state, _, err = readState(store)
+	s.NoError(err)
+	s.Equal(testutils.NewState(), state)
+
+	s.fake.objects["/bucket/terraform.tfstate"] = []byte("{}")
+	_, err = store.Read()
+	s.EqualError(err, "State bucket/terraform.tfstate does not match the digest stored in table locks")
+}
+
+func (s *S3StoreSuite) TestCopyFileToS3() {
+	orig := filepath.Join(testStateDir, "orig.tfstate")
+	s.NoError(writeTestState(orig, testutils.NewState()))
+
+	err := CopyTFState("file://"+orig, s.address("dr/terraform.tfstate"), "./testdata/filterConfig.json", Options{NoBackup: true})
+	s.NoError(err)
+
+	store, err := newS3Store(s.address("dr/terraform.tfstate"))
+	s.NoError(err)
+	state, _, err := readState(store)
+	s.NoError(err)
+	s.Equal(2+len(testutils.GlobalResources), len(state.Resources))
+	s.Equal(int64(1), state.Serial)
+	s.NotContains(s.fake.items, "bucket/dr/terraform.tfstate")
+}
+
+func (s *S3StoreSuite) TestUsesTerraformCloud() {
+	s.False(UsesTerraformCloud(s.address("terraform.tfstate"), "file://terraform.tfstate"))
+	s.True(UsesTerraformCloud(s.address("terraform.tfstate"), "test"))
+	s.True(UsesTerraformCloud("tfc://org/wks"))
+}
+
+func TestS3StoreSuite(t *testing.T) {
+	suite.Run(t, new(S3StoreSuite))
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mupuri/go-tfdr/internal/config"
+	"github.com/mupuri/go-tfdr/internal/models"
+)
+
+const (
+	tfcScheme  = "tfc://"
+	fileScheme = "file://"
+)
+
+// StateStore reads and writes the state of a single workspace or state file
+type StateStore interface {
+	// Read returns the json of the current state as stored, or nil if there is no state
+	Read() ([]byte, error)
+	// Write replaces the current state with stateBytes
+	Write(stateBytes []byte) error
+	Lock() error
+	Unlock() error
+	// BackupPath is the path, relative to the backup directory, that backups of the state are written to
+	BackupPath() string
+	String() string
+}
+
+// NewStateStore returns the state store at address. Addresses are either tfc://<org>/<workspace>,
+// file://<path>, s3://<bucket>/<key>, or the name of a workspace in the configured Terraform Cloud organization.
+func NewStateStore(address string) (StateStore, error) {
+	return newStateStore(address, config.GetConfig())
+}
+
+// newStateStore returns the state store at address. Terraform Cloud workspaces are read and written
+// with the host and token of c, and plain workspace names are looked up in the organization of c.
+func newStateStore(address string, c *config.Configuration) (StateStore, error) {
+	switch {
+	case strings.HasPrefix(address, tfcScheme):
+		parts := strings.Split(strings.TrimPrefix(address, tfcScheme), "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("Invalid state address %q. Expected tfc://<org>/<workspace>", address)
+		}
+		return &tfcStore{orgName: parts[0], workspaceName: parts[1], address: address, config: c}, nil
+	case strings.HasPrefix(address, fileScheme):
+		path := strings.TrimPrefix(address, fileScheme)
+		if path == "" {
+			return nil, fmt.Errorf("Invalid state address %q. Expected file://<path>", address)
+		}
+		return &fileStore{path: path, address: address}, nil
+	case strings.HasPrefix(address, s3Scheme):
+		return newS3Store(address)
+	case strings.Contains(address, "://"):
+		return nil, fmt.Errorf("Invalid state address %q. Unsupported scheme", address)
+	default:
+		if address == "" {
+			return nil, fmt.Errorf("State address is empty")
+		}
+		return &tfcStore{orgName: c.TerraformOrgName, workspaceName: address, address: address, config: c}, nil
+	}
+}
+
+// readState reads and decodes the current state of store, or returns nil if there is no state. The json
+// the state was decoded from is returned with it so backups keep what models.State does not hold.
+func readState(store StateStore) (*models.State, []byte, error) {
+	stateBytes, err := store.Read()
+	if err != nil || stateBytes == nil {
+		return nil, nil, err
+	}
+	state, err := decodeState(stateBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return state, stateBytes, nil
+}
+
+// writeState encodes state and writes it to store
+func writeState(store StateStore, state *models.State) error {
+	stateBytes, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Unable to marshal state object. Error: %v", err)
+	}
+	return store.Write(stateBytes)
+}
+
+func decodeState(stateBytes []byte) (*models.State, error) {
+	var state models.State
+	if err := json.Unmarshal(stateBytes, &state); err != nil {
+		return nil, fmt.Errorf("Cannot unmarshal state json. Err: %v", err)
+	}
+	return &state, nil
+}
+
+// UsesTerraformCloud returns true if any of the addresses is a Terraform Cloud workspace
+func UsesTerraformCloud(addresses ...string) bool {
+	for _, address := range addresses {
+		if strings.HasPrefix(address, tfcScheme) || !strings.Contains(address, "://") {
+			return true
+		}
+	}
+	return false
+}
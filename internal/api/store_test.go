@@ -0,0 +1,209 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/mupuri/go-tfdr/internal/config"
+	"github.com/mupuri/go-tfdr/internal/logging"
+	"github.com/mupuri/go-tfdr/internal/models"
+	"github.com/mupuri/go-tfdr/internal/testutils"
+	"github.com/stretchr/testify/suite"
+)
+
+const testStateDir = "./test-states"
+
+type StoreSuite struct {
+	suite.Suite
+}
+
+func (s *StoreSuite) SetupTest() {
+	os.Setenv("TF_TEAM_TOKEN", "test")
+	os.Setenv("TF_ORG_NAME", "team")
+	config.InitConfig("")
+	logging.InitLogger()
+	os.MkdirAll(testStateDir, 0755)
+	out = &bytes.Buffer{}
+}
+
+func (s *StoreSuite) TearDownTest() {
+	os.RemoveAll(testStateDir)
+	os.RemoveAll(testBackupDir)
+	os.Unsetenv("TF_TEAM_TOKEN")
+	os.Unsetenv("TF_ORG_NAME")
+}
+
+func writeTestState(path string, state *models.State) error {
+	bytes, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, bytes, 0644)
+}
+
+func (s *StoreSuite) TestNewStateStore() {
+	cases := []struct {
+		address  string
+		expected StateStore
+		err      bool
+	}{
+		{"test", &tfcStore{orgName: "team", workspaceName: "test", address: "test", config: config.GetConfig()}, false},
+		{"tfc://org/wks", &tfcStore{orgName: "org", workspaceName: "wks", address: "tfc://org/wks", config: config.GetConfig()}, false},
+		{"file://./terraform.tfstate", &fileStore{path: "./terraform.tfstate", address: "file://./terraform.tfstate"}, false},
+		{"file:///tmp/terraform.tfstate", &fileStore{path: "/tmp/terraform.tfstate", address: "file:///tmp/terraform.tfstate"}, false},
+		{"", nil, true},
+		{"tfc://org", nil, true},
+		{"tfc://org/wks/extra", nil, true},
+		{"file://", nil, true},
+		{"ftp://host/path", nil, true},
+	}
+
+	for _, c := range cases {
+		store, err := NewStateStore(c.address)
+		if c.err {
+			s.Error(err, c.address)
+		} else {
+			s.NoError(err, c.address)
+			s.Equal(c.expected, store, c.address)
+		}
+	}
+}
+
+func (s *StoreSuite) TestFileStore() {
+	store := &fileStore{path: filepath.Join(testStateDir, "terraform.tfstate")}
+
+	state, _, err := readState(store)
+	s.NoError(err)
+	s.Nil(state)
+
+	s.NoError(store.Lock())
+	s.Error(store.Lock())
+	s.NoError(writeState(store, testutils.NewState()))
+	s.NoError(store.Unlock())
+	s.NoFileExists(store.lockPath())
+
+	state, _, err = readState(store)
+	s.NoError(err)
+	s.Equal(testutils.NewState(), state)
+	s.Equal(filepath.Join("local", "terraform"), store.BackupPath())
+}
+
+func (s *StoreSuite) TestCopyFileToFile() {
+	orig := filepath.Join(testStateDir, "orig.tfstate")
+	dest := filepath.Join(testStateDir, "dest.tfstate")
+	s.NoError(writeTestState(orig, testutils.NewState()))
+
+	err := CopyTFState("file://"+orig, "file://"+dest, "./testdata/filterConfig.json", Options{BackupDir: testBackupDir})
+	s.NoError(err)
+
+	state, _, err := readState(&fileStore{path: dest})
+	s.NoError(err)
+	s.Equal(2+len(testutils.GlobalResources), len(state.Resources))
+	s.Equal(int64(1), state.Serial)
+	s.NoFileExists(dest + ".tfdr.lock")
+	s.DirExists(filepath.Join(testBackupDir, "local", "orig"))
+}
+
+func (s *StoreSuite) TestCopyFileToTFC() {
+	orig := filepath.Join(testStateDir, "orig.tfstate")
+	s.NoError(writeTestState(orig, testutils.NewState()))
+
+	httpmock.ActivateNonDefault(httpClient)
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "https://app.terraform.io/api/v2/ping", httpmock.NewStringResponder(204, ""))
+	posted := false
+	err := testutils.SetupWksMockHTTPResponses(&testutils.TfeTestWks{
+		Name:         "test2",
+		Exists:       true,
+		CsvResponder: httpmock.NewStringResponder(404, ""),
+		SvPostResponder: func(req *http.Request) (*http.Response, error) {
+			posted = true
+			state, err := testutils.DecodeStateFromBody(req)
+			s.NoError(err)
+			s.Equal(2+len(testutils.GlobalResources), len(state.Resources))
+			return testutils.NewJSONResponse("test2", "state-versions", "https://state")
+		},
+	})
+	s.NoError(err)
+
+	err = CopyTFState("file://"+orig, "tfc://team/test2", "./testdata/filterConfig.json", Options{NoBackup: true})
+	s.NoError(err)
+	s.True(posted)
+}
+
+func (s *StoreSuite) TestCopyTFCToFile() {
+	dest := filepath.Join(testStateDir, "dest.tfstate")
+
+	httpmock.ActivateNonDefault(httpClient)
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "https://app.terraform.io/api/v2/ping", httpmock.NewStringResponder(204, ""))
+	err := testutils.SetupWksMockHTTPResponses(&testutils.TfeTestWks{
+		Name:         "test1",
+		Exists:       true,
+		CurrentState: testutils.NewState(),
+		CsvResponder: testutils.NewResponder("test1", "state-versions", "https://state"),
+	})
+	s.NoError(err)
+
+	err = CopyTFState("test1", "file://"+dest, "./testdata/filterConfig.json", Options{NoBackup: true})
+	s.NoError(err)
+
+	state, _, err := readState(&fileStore{path: dest})
+	s.NoError(err)
+	s.Equal(2+len(testutils.GlobalResources), len(state.Resources))
+}
+
+func (s *StoreSuite) TestCopyAcrossOrganizationsAndHosts() {
+	httpmock.ActivateNonDefault(httpClient)
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "https://app.terraform.io/api/v2/ping", httpmock.NewStringResponder(204, ""))
+	err := testutils.SetupWksMockHTTPResponses(&testutils.TfeTestWks{
+		Name:         "test1",
+		Exists:       true,
+		CurrentState: testutils.NewState(),
+		CsvResponder: testutils.NewResponder("test1", "state-versions", "https://state"),
+	})
+	s.NoError(err)
+
+	tfeAPI := "https://tfe.example.com/api/v2"
+	httpmock.RegisterResponder("GET", tfeAPI+"/ping", httpmock.NewStringResponder(204, ""))
+	httpmock.RegisterResponder("GET", tfeAPI+"/organizations/dr/workspaces/test2", testutils.NewResponder("test2", "workspaces", ""))
+	httpmock.RegisterResponder("POST", tfeAPI+"/workspaces/test2/actions/lock", testutils.NewResponder("test2", "workspaces", ""))
+	httpmock.RegisterResponder("POST", tfeAPI+"/workspaces/test2/actions/unlock", testutils.NewResponder("test2", "workspaces", ""))
+	httpmock.RegisterResponder("GET", tfeAPI+"/workspaces/test2/current-state-version", httpmock.NewStringResponder(404, ""))
+	var destToken string
+	httpmock.RegisterResponder("POST", tfeAPI+"/workspaces/test2/state-versions", func(req *http.Request) (*http.Response, error) {
+		destToken = req.Header.Get("Authorization")
+		return testutils.NewJSONResponse("test2", "state-versions", "https://state")
+	})
+
+	err = CopyTFState("test1", "test2", "./testdata/filterConfig.json", Options{
+		NoBackup:          true,
+		DestinationConfig: config.GetConfig().Override("tfe.example.com", "dr", "dr-token"),
+	})
+	s.NoError(err)
+	s.Equal("Bearer dr-token", destToken)
+	s.Equal(1, httpmock.GetCallCountInfo()["GET https://app.terraform.io/api/v2/organizations/team/workspaces/test1"])
+}
+
+func (s *StoreSuite) TestDeleteFileLocked() {
+	path := filepath.Join(testStateDir, "terraform.tfstate")
+	s.NoError(writeTestState(path, testutils.NewState()))
+	store := &fileStore{path: path}
+	s.NoError(store.Lock())
+	defer store.Unlock()
+
+	err := DeleteTFStateResources("file://"+path, "./testdata/filterConfig.json", Options{NoBackup: true})
+	s.Error(err)
+	s.Contains(err.Error(), "Unable to lock state. Error: State file")
+}
+
+func TestStoreSuite(t *testing.T) {
+	suite.Run(t, new(StoreSuite))
+}
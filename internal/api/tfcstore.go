@@ -0,0 +1,79 @@
+package api
+
+import (
+	"path/filepath"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/mupuri/go-tfdr/internal/config"
+)
+
+// tfcStore is the state of a Terraform Cloud workspace
+type tfcStore struct {
+	orgName       string
+	workspaceName string
+	address       string
+	config        *config.Configuration
+
+	client *tfe.Client
+}
+
+// tfeClient creates the client of the store on first use and reuses it afterwards
+func (s *tfcStore) tfeClient() (*tfe.Client, error) {
+	if s.client == nil {
+		client, err := newTFEClient(s.config)
+		if err != nil {
+			return nil, err
+		}
+		s.client = client
+	}
+	return s.client, nil
+}
+
+func (s *tfcStore) Read() ([]byte, error) {
+	client, err := s.tfeClient()
+	if err != nil {
+		return nil, err
+	}
+	return pullTFState(client, s.orgName, s.workspaceName)
+}
+
+// ReadVersion returns the json of a previous state version from the history of the workspace
+func (s *tfcStore) ReadVersion(stateVersionID string) ([]byte, error) {
+	client, err := s.tfeClient()
+	if err != nil {
+		return nil, err
+	}
+	return pullTFStateVersion(client, s.orgName, s.workspaceName, stateVersionID)
+}
+
+func (s *tfcStore) Write(stateBytes []byte) error {
+	client, err := s.tfeClient()
+	if err != nil {
+		return err
+	}
+	return createTFStateVersion(client, stateBytes, s.orgName, s.workspaceName)
+}
+
+func (s *tfcStore) Lock() error {
+	client, err := s.tfeClient()
+	if err != nil {
+		return err
+	}
+	return lockTFWorkspace(client, s.orgName, s.workspaceName)
+}
+
+func (s *tfcStore) Unlock() error {
+	client, err := s.tfeClient()
+	if err != nil {
+		return err
+	}
+	return unlockTFWorkspace(client, s.orgName, s.workspaceName)
+}
+
+func (s *tfcStore) BackupPath() string {
+	return filepath.Join(s.orgName, s.workspaceName)
+}
+
+func (s *tfcStore) String() string {
+	return s.address
+}
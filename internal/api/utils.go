@@ -4,41 +4,21 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
-	"net/http"
 
 	"github.com/hashicorp/go-tfe"
-	"github.com/mupuri/go-tfdr/internal/config"
-	"github.com/mupuri/go-tfdr/internal/models"
 	"github.com/mupuri/go-tfdr/internal/tfdrerrors"
 )
 
-var httpClient = &http.Client{}
-
-func createTFStateVersion(state *models.State, workspaceName string) error {
-	c := config.GetConfig()
-
-	tfeConfig := &tfe.Config{
-		HTTPClient: httpClient,
-		Token:      c.TerraformTeamToken,
-	}
-
-	client, err := tfe.NewClient(tfeConfig)
-	if err != nil {
-		return fmt.Errorf("Cannot create tfe client. Err: %v", err)
-	}
-
-	workspace, err := client.Workspaces.Read(context.Background(), c.TerraformOrgName, workspaceName)
+func createTFStateVersion(client *tfe.Client, stateBytes []byte, orgName string, workspaceName string) error {
+	workspace, err := client.Workspaces.Read(context.Background(), orgName, workspaceName)
 	if err != nil {
 		return tfdrerrors.ErrGetWorkspace{Err: err}
 	}
 
-	client.Workspaces.Lock(context.Background(), workspace.ID, tfe.WorkspaceLockOptions{})
-
-	stateBytes, err := json.Marshal(state)
+	state, err := decodeState(stateBytes)
 	if err != nil {
-		return fmt.Errorf("Unable to unmarshal state object. Error: %v", err)
+		return err
 	}
 
 	versionMd5Bytes := fmt.Sprintf("%x", md5.Sum(stateBytes))
@@ -56,24 +36,11 @@ func createTFStateVersion(state *models.State, workspaceName string) error {
 	if err != nil {
 		return fmt.Errorf("Unable to create new state version. Err: %v", err)
 	}
-	client.Workspaces.Unlock(context.Background(), workspace.ID)
 	return nil
 }
 
-func pullTFState(workspaceName string) (*models.State, error) {
-	c := config.GetConfig()
-
-	tfeConfig := &tfe.Config{
-		HTTPClient: httpClient,
-		Token:      c.TerraformTeamToken,
-	}
-
-	client, err := tfe.NewClient(tfeConfig)
-	if err != nil {
-		return nil, fmt.Errorf("Cannot create tfe client. Err: %v", err)
-	}
-
-	workspace, err := client.Workspaces.Read(context.Background(), c.TerraformOrgName, workspaceName)
+func pullTFState(client *tfe.Client, orgName string, workspaceName string) ([]byte, error) {
+	workspace, err := client.Workspaces.Read(context.Background(), orgName, workspaceName)
 	if err != nil {
 		return nil, tfdrerrors.ErrGetWorkspace{Err: err}
 	}
@@ -86,17 +53,66 @@ func pullTFState(workspaceName string) (*models.State, error) {
 		return nil, tfdrerrors.ErrUnableToGetStateVersion{Err: err}
 	}
 
+	return downloadTFState(client, sv)
+}
+
+// pullTFStateVersion downloads a state version from the history of the workspace. State versions of
+// other workspaces are rejected.
+func pullTFStateVersion(client *tfe.Client, orgName string, workspaceName string, stateVersionID string) ([]byte, error) {
+	options := tfe.StateVersionListOptions{
+		Organization: tfe.String(orgName),
+		Workspace:    tfe.String(workspaceName),
+	}
+	found := false
+	for !found {
+		svl, err := client.StateVersions.List(context.Background(), options)
+		if err != nil {
+			return nil, tfdrerrors.ErrUnableToGetStateVersion{Err: err}
+		}
+		for _, sv := range svl.Items {
+			found = found || sv.ID == stateVersionID
+		}
+		if svl.Pagination == nil || svl.NextPage == 0 {
+			break
+		}
+		options.PageNumber = svl.NextPage
+	}
+	if !found {
+		return nil, tfdrerrors.ErrStateVersionNotInWorkspace{StateVersionID: stateVersionID, Workspace: orgName + "/" + workspaceName}
+	}
+
+	sv, err := client.StateVersions.Read(context.Background(), stateVersionID)
+	if err != nil {
+		return nil, tfdrerrors.ErrUnableToGetStateVersion{Err: err}
+	}
+
+	return downloadTFState(client, sv)
+}
+
+func downloadTFState(client *tfe.Client, sv *tfe.StateVersion) ([]byte, error) {
 	s, err := client.StateVersions.Download(context.Background(), sv.DownloadURL)
 	if err != nil {
 		return nil, tfdrerrors.ErrUnableToDownloadState{Err: err}
 	}
+	return s, nil
+}
 
-	var state models.State
+func lockTFWorkspace(client *tfe.Client, orgName string, workspaceName string) error {
+	workspace, err := client.Workspaces.Read(context.Background(), orgName, workspaceName)
+	if err != nil {
+		return tfdrerrors.ErrGetWorkspace{Err: err}
+	}
+
+	_, err = client.Workspaces.Lock(context.Background(), workspace.ID, tfe.WorkspaceLockOptions{})
+	return err
+}
 
-	err = json.Unmarshal(s, &state)
+func unlockTFWorkspace(client *tfe.Client, orgName string, workspaceName string) error {
+	workspace, err := client.Workspaces.Read(context.Background(), orgName, workspaceName)
 	if err != nil {
-		return nil, fmt.Errorf("Cannot unmarshal downloaded state json. Err: : %v", err)
+		return tfdrerrors.ErrGetWorkspace{Err: err}
 	}
 
-	return &state, nil
+	_, err = client.Workspaces.Unlock(context.Background(), workspace.ID)
+	return err
 }
@@ -1,9 +1,12 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -16,6 +19,8 @@ import (
 	"github.com/stretchr/testify/suite"
 )
 
+const testBackupDir = "./test-backups"
+
 type UtilSuite struct {
 	suite.Suite
 }
@@ -38,20 +43,28 @@ func (s *UtilSuite) TearDownTest() {
 	os.Unsetenv("TF_ORG_NAME")
 }
 
+func (s *UtilSuite) client() *tfe.Client {
+	client, err := newTFEClient(config.GetConfig())
+	s.Require().NoError(err)
+	return client
+}
+
 func (s *UtilSuite) TestCreateTFStateVersion() {
-	state := testutils.NewState()
+	state, err := json.Marshal(testutils.NewState())
+	s.NoError(err)
 	httpmock.RegisterResponder("POST", "https://app.terraform.io/api/v2/workspaces/test/state-versions", testutils.NewResponder("test", "state-versions", "https://state"))
 
-	err := createTFStateVersion(state, "test")
+	err = createTFStateVersion(s.client(), state, "team", "test")
 	s.NoError(err)
 }
 
 func (s *UtilSuite) TestCreateTFStateVersionNoWorkspace() {
-	state := testutils.NewState()
+	state, err := json.Marshal(testutils.NewState())
+	s.NoError(err)
 	httpmock.RegisterResponder("POST", "https://app.terraform.io/api/v2/workspaces/test/state-versions", testutils.NewResponder("test", "state-versions", "https://state"))
 	httpmock.RegisterResponder("GET", "https://app.terraform.io/api/v2/organizations/team/workspaces/not-found", httpmock.NewStringResponder(404, ""))
 
-	err := createTFStateVersion(state, "not-found")
+	err = createTFStateVersion(s.client(), state, "team", "not-found")
 	s.Error(err)
 	s.True(errors.Is(err, tfdrerrors.ErrGetWorkspace{
 		Err: tfe.ErrResourceNotFound,
@@ -65,15 +78,14 @@ func (s *UtilSuite) TestPullTFState() {
 
 	httpmock.RegisterResponder("GET", "https://app.terraform.io/api/v2/workspaces/test/current-state-version", testutils.NewResponder("test", "state-versions", "https://state"))
 	httpmock.RegisterResponder("GET", "https://state", httpmock.NewStringResponder(200, string(currentState)))
-	st, err := pullTFState("test")
+	st, err := pullTFState(s.client(), "team", "test")
 	s.NoError(err)
-	s.NotNil(st)
-	s.Equal(testutils.DefaultNumResources(), len(st.Resources))
+	s.Equal(currentState, st)
 }
 
 func (s *UtilSuite) TestPullTFStateNoState() {
 	httpmock.RegisterResponder("GET", "https://app.terraform.io/api/v2/workspaces/test/current-state-version", httpmock.NewStringResponder(404, ""))
-	st, err := pullTFState("test")
+	st, err := pullTFState(s.client(), "team", "test")
 	s.NoError(err)
 	s.Nil(st)
 }
@@ -81,7 +93,7 @@ func (s *UtilSuite) TestPullTFStateNoState() {
 func (s *UtilSuite) TestPullTFStateErrGetCurrentState() {
 	httpmock.RegisterResponder("GET", "https://app.terraform.io/api/v2/workspaces/test/current-state-version", httpmock.NewErrorResponder(errors.New("Error getting current state version")))
 
-	st, err := pullTFState("test")
+	st, err := pullTFState(s.client(), "team", "test")
 	s.Error(err)
 	s.True(strings.Contains(err.Error(), "Cannot get current state. Error:"))
 	s.Nil(st)
@@ -90,12 +102,39 @@ func (s *UtilSuite) TestPullTFStateErrGetCurrentState() {
 func (s *UtilSuite) TestPullTFStateErrDownloadState() {
 	httpmock.RegisterResponder("GET", "https://app.terraform.io/api/v2/workspaces/test/current-state-version", testutils.NewResponder("test", "state-versions", "https://state"))
 	httpmock.RegisterResponder("GET", "https://state", httpmock.NewErrorResponder(errors.New("Error downloading state")))
-	st, err := pullTFState("test")
+	st, err := pullTFState(s.client(), "team", "test")
 	s.Error(err)
 	s.True(strings.Contains(err.Error(), "Cannot download state. Error:"))
 	s.Nil(st)
 }
 
+func (s *UtilSuite) TestBackupState() {
+	var buf bytes.Buffer
+	out = &buf
+	defer os.RemoveAll(testBackupDir)
+
+	state := []byte(`{"version":4,"serial":3,"resources":[{"mode":"managed","type":"aws_instance","name":"web","instances":[{"status":"tainted","attributes":{}}]}]}`)
+	err := backupState(state, &tfcStore{orgName: "team", workspaceName: "test", address: "test"}, Options{BackupDir: testBackupDir})
+	s.NoError(err)
+	s.Contains(buf.String(), "Backed up state of test to test-backups/team/test/")
+	files, err := ioutil.ReadDir(filepath.Join(testBackupDir, "team", "test"))
+	s.NoError(err)
+	s.Equal(1, len(files))
+	backup, err := ioutil.ReadFile(filepath.Join(testBackupDir, "team", "test", files[0].Name()))
+	s.NoError(err)
+	s.Equal(state, backup)
+}
+
+func (s *UtilSuite) TestBackupStateDisabled() {
+	var buf bytes.Buffer
+	out = &buf
+
+	err := backupState([]byte("{}"), &tfcStore{orgName: "team", workspaceName: "test", address: "test"}, Options{BackupDir: testBackupDir, NoBackup: true})
+	s.NoError(err)
+	s.Empty(buf.String())
+	s.NoDirExists(testBackupDir)
+}
+
 func TestUtilSuite(t *testing.T) {
 	suite.Run(t, new(UtilSuite))
 }
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/mupuri/go-tfdr/internal/config"
+	"github.com/mupuri/go-tfdr/internal/tfdrerrors"
+)
+
+// ValidateConnection checks that the Terraform Cloud or Enterprise host of c can be reached, that the
+// team token is accepted and that the token has access to the organization of c
+func ValidateConnection(c *config.Configuration) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	client, err := newTFEClient(c)
+	if err != nil {
+		return tfdrerrors.ErrUnableToConnect{Address: c.Host(), Err: err}
+	}
+
+	account := "token"
+	user, err := client.Users.ReadCurrent(context.Background())
+	switch {
+	case err == tfe.ErrUnauthorized:
+		return tfdrerrors.ErrInvalidToken{Err: err}
+	case err == tfe.ErrResourceNotFound:
+		// organization tokens have no account
+	case err != nil:
+		return tfdrerrors.ErrUnableToConnect{Address: c.Host(), Err: err}
+	default:
+		account = user.Username
+	}
+
+	_, err = client.Organizations.Read(context.Background(), c.TerraformOrgName)
+	switch {
+	case err == tfe.ErrResourceNotFound || err == tfe.ErrUnauthorized:
+		return tfdrerrors.ErrOrganizationNotAccessible{Org: c.TerraformOrgName, Err: err}
+	case err != nil:
+		return tfdrerrors.ErrUnableToConnect{Address: c.Host(), Err: err}
+	}
+
+	fmt.Fprintf(out, "Configuration is valid. %s has access to organization %s on %s\n", account, c.TerraformOrgName, c.Host())
+	return nil
+}
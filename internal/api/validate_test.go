@@ -0,0 +1,124 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/jarcoal/httpmock"
+	"github.com/mupuri/go-tfdr/internal/config"
+	"github.com/mupuri/go-tfdr/internal/logging"
+	"github.com/mupuri/go-tfdr/internal/tfdrerrors"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testUserJSON = `{"data": {"id": "user-1", "type": "users", "attributes": {"username": "api-team_123"}}}`
+	testOrgJSON  = `{"data": {"id": "team", "type": "organizations", "attributes": {"name": "team"}}}`
+)
+
+type ValidateSuite struct {
+	suite.Suite
+}
+
+func (s *ValidateSuite) SetupTest() {
+	os.Setenv("TF_TEAM_TOKEN", "test")
+	os.Setenv("TF_ORG_NAME", "team")
+	config.InitConfig("")
+	logging.InitLogger()
+}
+
+func (s *ValidateSuite) TearDownTest() {
+	os.Unsetenv("TF_TEAM_TOKEN")
+	os.Unsetenv("TF_ORG_NAME")
+}
+
+func (s *ValidateSuite) TestValidateConnection() {
+	cases := []struct {
+		ping              httpmock.Responder
+		account           httpmock.Responder
+		organization      httpmock.Responder
+		outputContains    string
+		shouldErr         bool
+		errValidationFunc func(error) bool
+		errMessage        string
+	}{
+		{
+			ping:           httpmock.NewStringResponder(204, ""),
+			account:        httpmock.NewStringResponder(200, testUserJSON),
+			organization:   httpmock.NewStringResponder(200, testOrgJSON),
+			outputContains: "Configuration is valid. api-team_123 has access to organization team on app.terraform.io\n",
+			errMessage:     "Test valid configuration failed",
+		},
+		{
+			ping:           httpmock.NewStringResponder(204, ""),
+			account:        httpmock.NewStringResponder(404, ""),
+			organization:   httpmock.NewStringResponder(200, testOrgJSON),
+			outputContains: "Configuration is valid. token has access to organization team on app.terraform.io\n",
+			errMessage:     "Test valid organization token failed",
+		},
+		{
+			ping:      httpmock.NewErrorResponder(errors.New("connection refused")),
+			shouldErr: true,
+			errValidationFunc: func(err error) bool {
+				var connectErr tfdrerrors.ErrUnableToConnect
+				return errors.As(err, &connectErr) && connectErr.Address == "app.terraform.io"
+			},
+			errMessage: "Test unreachable host failed",
+		},
+		{
+			ping:      httpmock.NewStringResponder(204, ""),
+			account:   httpmock.NewStringResponder(401, ""),
+			shouldErr: true,
+			errValidationFunc: func(err error) bool {
+				return errors.Is(err, tfdrerrors.ErrInvalidToken{Err: tfe.ErrUnauthorized})
+			},
+			errMessage: "Test invalid token failed",
+		},
+		{
+			ping:         httpmock.NewStringResponder(204, ""),
+			account:      httpmock.NewStringResponder(200, testUserJSON),
+			organization: httpmock.NewStringResponder(404, ""),
+			shouldErr:    true,
+			errValidationFunc: func(err error) bool {
+				return errors.Is(err, tfdrerrors.ErrOrganizationNotAccessible{Org: "team", Err: tfe.ErrResourceNotFound})
+			},
+			errMessage: "Test inaccessible organization failed",
+		},
+	}
+
+	for _, c := range cases {
+		httpmock.ActivateNonDefault(httpClient)
+		httpmock.RegisterResponder("GET", "https://app.terraform.io/api/v2/ping", c.ping)
+		if c.account != nil {
+			httpmock.RegisterResponder("GET", "https://app.terraform.io/api/v2/account/details", c.account)
+		}
+		if c.organization != nil {
+			httpmock.RegisterResponder("GET", "https://app.terraform.io/api/v2/organizations/team", c.organization)
+		}
+		var buf bytes.Buffer
+		out = &buf
+
+		err := ValidateConnection(config.GetConfig())
+		if c.shouldErr {
+			s.Error(err, c.errMessage)
+			s.True(c.errValidationFunc(err), fmt.Sprintf("%v. Invalid error returned: %v", c.errMessage, err))
+		} else {
+			s.NoError(err, c.errMessage)
+			s.Equal(c.outputContains, buf.String(), c.errMessage)
+		}
+		httpmock.DeactivateAndReset()
+	}
+}
+
+func (s *ValidateSuite) TestValidateConnectionMissingToken() {
+	err := ValidateConnection(&config.Configuration{TerraformOrgName: "team"})
+	s.True(errors.Is(err, config.ErrTFTeamTokenRequired))
+}
+
+func TestValidateSuite(t *testing.T) {
+	suite.Run(t, new(ValidateSuite))
+}
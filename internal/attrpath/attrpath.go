@@ -0,0 +1,211 @@
+package attrpath
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type stepKind int
+
+const (
+	keyStep stepKind = iota
+	indexStep
+	wildcardStep
+)
+
+// Step is a single map key, list index or wildcard in an attribute path
+type Step struct {
+	kind  stepKind
+	key   string
+	index int
+}
+
+func (s Step) String() string {
+	switch s.kind {
+	case indexStep:
+		return fmt.Sprintf("[%d]", s.index)
+	case wildcardStep:
+		return "[*]"
+	default:
+		if s.key == "*" || strings.ContainsAny(s.key, `.[]"`) {
+			return fmt.Sprintf("[%q]", s.key)
+		}
+		return s.key
+	}
+}
+
+// Path is a parsed attribute path such as tags.Environment, vpc_config[0].subnet_ids or
+// ingress[*].cidr_blocks
+type Path []Step
+
+func (p Path) String() string {
+	var sb strings.Builder
+	for i, s := range p {
+		if i > 0 && s.kind == keyStep && !strings.HasPrefix(s.String(), "[") {
+			sb.WriteString(".")
+		}
+		sb.WriteString(s.String())
+	}
+	return sb.String()
+}
+
+// Parse parses a dotted attribute path. Map keys are separated by dots, list indexes are written
+// in brackets, and * or [*] selects every element of a list or map. Keys containing dots can be
+// quoted in brackets, e.g. tags["kubernetes.io/cluster"].
+func Parse(path string) (Path, error) {
+	if path == "" {
+		return nil, fmt.Errorf("Attribute path is empty")
+	}
+
+	p := make(Path, 0)
+	for i := 0; i < len(path); {
+		switch {
+		case path[i] == '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("Invalid attribute path %q. Missing ] after position %d", path, i)
+			}
+			inner := path[i+1 : i+end]
+			if strings.HasPrefix(inner, `"`) {
+				key, err := strconv.Unquote(inner)
+				if err != nil {
+					return nil, fmt.Errorf("Invalid attribute path %q. Invalid quoted key %s", path, inner)
+				}
+				p = append(p, Step{kind: keyStep, key: key})
+			} else if inner == "*" {
+				p = append(p, Step{kind: wildcardStep})
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("Invalid attribute path %q. Invalid index [%s]", path, inner)
+				}
+				p = append(p, Step{kind: indexStep, index: index})
+			}
+			i += end + 1
+		case path[i] == '.':
+			if i == 0 || i == len(path)-1 || path[i+1] == '.' || path[i+1] == '[' {
+				return nil, fmt.Errorf("Invalid attribute path %q. Unexpected . at position %d", path, i)
+			}
+			i++
+		default:
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			key := path[i : i+end]
+			if key == "*" {
+				p = append(p, Step{kind: wildcardStep})
+			} else {
+				p = append(p, Step{kind: keyStep, key: key})
+			}
+			i += end
+		}
+	}
+	return p, nil
+}
+
+// Set replaces the value at path in attributes. Every step of the path must already exist;
+// wildcards replace the value in every element they select.
+func Set(attributes map[string]interface{}, path Path, value func() interface{}) error {
+	return set(attributes, path, 0, value)
+}
+
+func set(current interface{}, path Path, i int, value func() interface{}) error {
+	step := path[i]
+	last := i == len(path)-1
+	notFound := func() error {
+		return fmt.Errorf("Attribute %s does not exist", path[:i+1])
+	}
+
+	switch c := current.(type) {
+	case map[string]interface{}:
+		if step.kind == indexStep {
+			return notFound()
+		}
+		keys := []string{step.key}
+		if step.kind == wildcardStep {
+			keys = make([]string, 0, len(c))
+			for k := range c {
+				keys = append(keys, k)
+			}
+		} else if _, ok := c[step.key]; !ok {
+			return notFound()
+		}
+		for _, k := range keys {
+			if last {
+				c[k] = value()
+			} else if err := set(c[k], path, i+1, value); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if step.kind == keyStep {
+			return notFound()
+		}
+		indexes := []int{step.index}
+		if step.kind == wildcardStep {
+			indexes = make([]int, len(c))
+			for j := range c {
+				indexes[j] = j
+			}
+		} else if step.index >= len(c) {
+			return notFound()
+		}
+		for _, j := range indexes {
+			if last {
+				c[j] = value()
+			} else if err := set(c[j], path, i+1, value); err != nil {
+				return err
+			}
+		}
+	default:
+		return notFound()
+	}
+	return nil
+}
+
+// Get returns the values at path in attributes. Wildcards return the value of every element they
+// select. Steps that do not exist return no values.
+func Get(attributes map[string]interface{}, path Path) []interface{} {
+	return get(attributes, path, 0, make([]interface{}, 0))
+}
+
+func get(current interface{}, path Path, i int, values []interface{}) []interface{} {
+	if i == len(path) {
+		return append(values, current)
+	}
+	step := path[i]
+
+	switch c := current.(type) {
+	case map[string]interface{}:
+		switch step.kind {
+		case wildcardStep:
+			keys := make([]string, 0, len(c))
+			for k := range c {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				values = get(c[k], path, i+1, values)
+			}
+		case keyStep:
+			if v, ok := c[step.key]; ok {
+				values = get(v, path, i+1, values)
+			}
+		}
+	case []interface{}:
+		switch step.kind {
+		case wildcardStep:
+			for _, v := range c {
+				values = get(v, path, i+1, values)
+			}
+		case indexStep:
+			if step.index < len(c) {
+				values = get(c[step.index], path, i+1, values)
+			}
+		}
+	}
+	return values
+}
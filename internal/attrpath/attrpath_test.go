@@ -0,0 +1,124 @@
+package attrpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSuite struct {
+	suite.Suite
+}
+
+func TestRunSuite(t *testing.T) {
+	suite.Run(t, new(TestSuite))
+}
+
+func (s *TestSuite) TestParse() {
+	cases := []struct {
+		path     string
+		expected Path
+		err      bool
+	}{
+		{"attr1", Path{{kind: keyStep, key: "attr1"}}, false},
+		{"tags.Environment", Path{{kind: keyStep, key: "tags"}, {kind: keyStep, key: "Environment"}}, false},
+		{"vpc_config[0].subnet_ids", Path{{kind: keyStep, key: "vpc_config"}, {kind: indexStep, index: 0}, {kind: keyStep, key: "subnet_ids"}}, false},
+		{"ingress[*].cidr_blocks", Path{{kind: keyStep, key: "ingress"}, {kind: wildcardStep}, {kind: keyStep, key: "cidr_blocks"}}, false},
+		{"tags[*]", Path{{kind: keyStep, key: "tags"}, {kind: wildcardStep}}, false},
+		{`tags["kubernetes.io/cluster"]`, Path{{kind: keyStep, key: "tags"}, {kind: keyStep, key: "kubernetes.io/cluster"}}, false},
+		{"", nil, true},
+		{"tags.", nil, true},
+		{"tags..a", nil, true},
+		{"list[0", nil, true},
+		{"list[a]", nil, true},
+		{"list[-1]", nil, true},
+	}
+
+	p, err := Parse("tags.*")
+	s.NoError(err)
+	s.Equal(Path{{kind: keyStep, key: "tags"}, {kind: wildcardStep}}, p)
+
+	for _, c := range cases {
+		p, err := Parse(c.path)
+		if c.err {
+			s.Error(err, c.path)
+		} else {
+			s.NoError(err, c.path)
+			s.Equal(c.expected, p, c.path)
+			s.Equal(c.path, p.String())
+		}
+	}
+}
+
+func newAttributes() map[string]interface{} {
+	return map[string]interface{}{
+		"name": "a",
+		"tags": map[string]interface{}{"Environment": "prod", "Team": "dr"},
+		"list": []interface{}{
+			map[string]interface{}{"value": "1"},
+			map[string]interface{}{"value": "2"},
+		},
+	}
+}
+
+func (s *TestSuite) TestSet() {
+	cases := []struct {
+		path     string
+		expected func(map[string]interface{})
+		err      string
+	}{
+		{"name", func(a map[string]interface{}) { a["name"] = "new" }, ""},
+		{"tags.Environment", func(a map[string]interface{}) { a["tags"].(map[string]interface{})["Environment"] = "new" }, ""},
+		{"tags.*", func(a map[string]interface{}) {
+			a["tags"] = map[string]interface{}{"Environment": "new", "Team": "new"}
+		}, ""},
+		{"list[1].value", func(a map[string]interface{}) { a["list"].([]interface{})[1].(map[string]interface{})["value"] = "new" }, ""},
+		{"list[*].value", func(a map[string]interface{}) {
+			a["list"] = []interface{}{map[string]interface{}{"value": "new"}, map[string]interface{}{"value": "new"}}
+		}, ""},
+		{"missing", nil, "Attribute missing does not exist"},
+		{"tags.Owner", nil, "Attribute tags.Owner does not exist"},
+		{"list[2].value", nil, "Attribute list[2] does not exist"},
+		{"list.value", nil, "Attribute list.value does not exist"},
+		{"name.first", nil, "Attribute name.first does not exist"},
+	}
+
+	for _, c := range cases {
+		p, err := Parse(c.path)
+		s.NoError(err, c.path)
+		attributes := newAttributes()
+		err = Set(attributes, p, func() interface{} { return "new" })
+		if c.err != "" {
+			s.EqualError(err, c.err, c.path)
+			s.Equal(newAttributes(), attributes, c.path)
+		} else {
+			s.NoError(err, c.path)
+			expected := newAttributes()
+			c.expected(expected)
+			s.Equal(expected, attributes, c.path)
+		}
+	}
+}
+
+func (s *TestSuite) TestGet() {
+	cases := []struct {
+		path     string
+		expected []interface{}
+	}{
+		{"name", []interface{}{"a"}},
+		{"tags.Environment", []interface{}{"prod"}},
+		{"tags.*", []interface{}{"prod", "dr"}},
+		{"list[1].value", []interface{}{"2"}},
+		{"list[*].value", []interface{}{"1", "2"}},
+		{"missing", []interface{}{}},
+		{"list[2].value", []interface{}{}},
+		{"list.value", []interface{}{}},
+		{"name.first", []interface{}{}},
+	}
+
+	for _, c := range cases {
+		p, err := Parse(c.path)
+		s.NoError(err, c.path)
+		s.Equal(c.expected, Get(newAttributes(), p), c.path)
+	}
+}
@@ -0,0 +1,51 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mupuri/go-tfdr/internal/models"
+)
+
+var now = time.Now
+
+// defaultDir returns the directory backups are written to when no directory is configured
+func defaultDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("Unable to find home directory. Err: %v", err)
+	}
+	return filepath.Join(homeDir, ".tfdr", "backups"), nil
+}
+
+// Write saves the json of a state, as it was read, in <dir>/<name>/ and returns the path of the backup
+// file. name is usually <org>/<workspace>.
+func Write(dir string, name string, stateBytes []byte) (string, error) {
+	var state models.State
+	if err := json.Unmarshal(stateBytes, &state); err != nil {
+		return "", fmt.Errorf("Cannot unmarshal state json. Err: %v", err)
+	}
+
+	if dir == "" {
+		d, err := defaultDir()
+		if err != nil {
+			return "", err
+		}
+		dir = d
+	}
+	backupDir := filepath.Join(dir, name)
+	if err := os.MkdirAll(backupDir, 0700); err != nil {
+		return "", fmt.Errorf("Unable to create backup directory %s. Err: %v", backupDir, err)
+	}
+
+	fileName := fmt.Sprintf("%s-%d.json", now().UTC().Format("20060102T150405Z"), state.Serial)
+	backupFile := filepath.Join(backupDir, fileName)
+	if err := ioutil.WriteFile(backupFile, stateBytes, 0600); err != nil {
+		return "", fmt.Errorf("Unable to write backup file %s. Err: %v", backupFile, err)
+	}
+	return backupFile, nil
+}
@@ -0,0 +1,71 @@
+package backup
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mupuri/go-tfdr/internal/models"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSuite struct {
+	suite.Suite
+}
+
+func TestRunSuite(t *testing.T) {
+	suite.Run(t, new(TestSuite))
+}
+
+func (s *TestSuite) TestWrite() {
+	dir := "./test-backups"
+	defer os.RemoveAll(dir)
+	now = func() time.Time { return time.Date(2020, 10, 1, 12, 30, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	state, err := json.Marshal(&models.State{Version: 4, Serial: 7, Lineage: "test"})
+	s.NoError(err)
+	backupFile, err := Write(dir, filepath.Join("org", "wks"), state)
+	s.NoError(err)
+	s.Equal(filepath.Join(dir, "org", "wks", "20201001T123000Z-7.json"), backupFile)
+
+	bytes, err := ioutil.ReadFile(backupFile)
+	s.NoError(err)
+	s.Equal(state, bytes)
+}
+
+func (s *TestSuite) TestWriteKeepsState() {
+	dir := "./test-backups"
+	defer os.RemoveAll(dir)
+
+	state := []byte(`{"version":4,"serial":3,"lineage":"test","check_results":null,"resources":[{"mode":"managed","type":"aws_instance","name":"web","instances":[{"status":"tainted","sensitive_attributes":[],"attributes":{"id":"i-1"}},{"deposed":"00000001","attributes":{"id":"i-0"}}]}]}`)
+	backupFile, err := Write(dir, filepath.Join("org", "wks"), state)
+	s.NoError(err)
+
+	bytes, err := ioutil.ReadFile(backupFile)
+	s.NoError(err)
+	s.Equal(state, bytes, "Test backup keeps the state as it was read failed")
+}
+
+func (s *TestSuite) TestWriteInvalidState() {
+	dir := "./test-backups"
+	defer os.RemoveAll(dir)
+
+	_, err := Write(dir, filepath.Join("org", "wks"), []byte("not json"))
+	s.Error(err)
+	s.NoDirExists(filepath.Join(dir, "org", "wks"))
+}
+
+func (s *TestSuite) TestWriteDefaultDir() {
+	dir := "./test-home"
+	os.Setenv("HOME", dir)
+	defer os.RemoveAll(dir)
+
+	backupFile, err := Write("", filepath.Join("org", "wks"), []byte("{}"))
+	s.NoError(err)
+	s.Equal(filepath.Join(dir, ".tfdr", "backups", "org", "wks"), filepath.Dir(backupFile))
+	s.FileExists(backupFile)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"os"
 	"strings"
 
 	"github.com/mupuri/go-tfdr/internal/config/file"
@@ -15,10 +16,14 @@ import (
 
 var configuration *Configuration
 
+// base is the configuration before the active profile is applied
+var base *Configuration
+
 // ErrTFTeamTokenRequired &
 var (
 	ErrTFTeamTokenRequired = errors.New("Terraform team token is required")
-	ErrTFOrgNameRequired   = errors.New("Terraform team token is required")
+	ErrTFOrgNameRequired   = errors.New("Terraform org name is required")
+	ErrProfileNotFound     = errors.New("Profile not found")
 	viper                  = vpr.New()
 )
 
@@ -27,6 +32,22 @@ type Configuration struct {
 	TerraformTeamToken string `mapstructure:"tf_team_token" yaml:"tf_team_token"`
 	TerraformOrgName   string `mapstructure:"tf_org_name" yaml:"tf_org_name"`
 	LogLevel           string `mapstructure:"tf_state_copy_log_level" yaml:"tf_state_copy_log_level"`
+	// TerraformAddress is the address of a Terraform Enterprise instance. Empty means app.terraform.io
+	TerraformAddress string `mapstructure:"tf_address" yaml:"tf_address,omitempty"`
+	// CACertFile is a PEM bundle of CAs trusted in addition to the system CAs
+	CACertFile         string `mapstructure:"tf_ca_cert_file" yaml:"tf_ca_cert_file,omitempty"`
+	InsecureSkipVerify bool   `mapstructure:"tf_insecure_skip_verify" yaml:"tf_insecure_skip_verify,omitempty"`
+	HTTPProxy          string `mapstructure:"tf_http_proxy" yaml:"tf_http_proxy,omitempty"`
+	// Profiles are named sets of settings applied on top of the top level settings
+	Profiles map[string]*Configuration `mapstructure:"profiles" yaml:"profiles,omitempty"`
+	// Profile is the name of the active profile
+	Profile string `mapstructure:"-" yaml:"profile,omitempty"`
+	// CredentialsHelper is a terraform credentials helper command used when no token is configured
+	CredentialsHelper string `mapstructure:"tf_credentials_helper" yaml:"tf_credentials_helper,omitempty"`
+	// TokenSource describes where the team token was read from
+	TokenSource string `mapstructure:"-" yaml:"tf_team_token_source,omitempty"`
+
+	credentialsErr error
 }
 
 // GetConfig &
@@ -34,17 +55,82 @@ func GetConfig() *Configuration {
 	return configuration
 }
 
+// GetProfile returns the configuration with the named profile applied on top of the top level settings
+func GetProfile(name string) (*Configuration, error) {
+	profile, ok := base.Profiles[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	c := base.merge(profile)
+	c.Profile = name
+	return c, nil
+}
+
 // ValidateConfig &
 func ValidateConfig() error {
-	if len(configuration.TerraformTeamToken) == 0 {
+	if configuration.Profile != "" {
+		if _, ok := base.Profiles[strings.ToLower(configuration.Profile)]; !ok {
+			return fmt.Errorf("%w: %s", ErrProfileNotFound, configuration.Profile)
+		}
+	}
+	return configuration.Validate()
+}
+
+// Validate &
+func (c *Configuration) Validate() error {
+	if len(c.TerraformTeamToken) == 0 {
+		if c.credentialsErr != nil {
+			return fmt.Errorf("%w: %v", ErrTFTeamTokenRequired, c.credentialsErr)
+		}
 		return ErrTFTeamTokenRequired
 	}
-	if len(configuration.TerraformOrgName) == 0 {
+	if len(c.TerraformOrgName) == 0 {
 		return ErrTFOrgNameRequired
 	}
 	return nil
 }
 
+// Override returns a copy of the configuration with the non empty values of address, orgName and token.
+// When address changes the host and no token is given, the token is looked up again for the new host
+// so a token is never sent to a host it was not configured for.
+func (c *Configuration) Override(address, orgName, token string) *Configuration {
+	override := *c
+	if address != "" {
+		override.TerraformAddress = address
+	}
+	if orgName != "" {
+		override.TerraformOrgName = orgName
+	}
+	if token != "" {
+		override.TerraformTeamToken = token
+		override.TokenSource = TokenSourceConfig
+	} else if override.Host() != c.Host() {
+		override.TerraformTeamToken = ""
+		override.TokenSource = ""
+		override.resolveToken()
+	}
+	return &override
+}
+
+// merge returns a copy of the configuration with the non empty settings of profile
+func (c *Configuration) merge(profile *Configuration) *Configuration {
+	merged := c.Override(profile.TerraformAddress, profile.TerraformOrgName, profile.TerraformTeamToken)
+	if profile.LogLevel != "" {
+		merged.LogLevel = profile.LogLevel
+	}
+	if profile.CACertFile != "" {
+		merged.CACertFile = profile.CACertFile
+	}
+	if profile.InsecureSkipVerify {
+		merged.InsecureSkipVerify = true
+	}
+	if profile.HTTPProxy != "" {
+		merged.HTTPProxy = profile.HTTPProxy
+	}
+	merged.Profiles = nil
+	return merged
+}
+
 // New &
 func New() *Configuration {
 	c := Configuration{
@@ -55,6 +141,14 @@ func New() *Configuration {
 
 // InitConfig &
 func InitConfig(cfgFile string) {
+	InitConfigWithProfile(cfgFile, "")
+}
+
+// InitConfigWithProfile reads the configuration and applies the named profile. An empty profile
+// name falls back to the TFDR_PROFILE environment variable. Settings of the profile take precedence
+// over environment variables and top level settings. A profile that does not exist is reported by
+// ValidateConfig so commands that create profiles can still run.
+func InitConfigWithProfile(cfgFile string, profile string) {
 	configuration = New()
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
@@ -67,32 +161,94 @@ func InitConfig(cfgFile string) {
 	_ = viper.BindEnv("TF_TEAM_TOKEN")
 	_ = viper.BindEnv("TF_ORG_NAME")
 	_ = viper.BindEnv("TF_STATE_COPY_LOG_LEVEL")
+	_ = viper.BindEnv("TF_ADDRESS")
+	_ = viper.BindEnv("TF_CA_CERT_FILE")
+	_ = viper.BindEnv("TF_INSECURE_SKIP_VERIFY")
+	_ = viper.BindEnv("TF_HTTP_PROXY")
+	_ = viper.BindEnv("TF_CREDENTIALS_HELPER")
 	viper.AutomaticEnv()
 	_ = viper.ReadInConfig()
 
 	if err := viper.Unmarshal(&configuration); err != nil {
 		log.Fatalf("ERROR: Error reading config: %v", err)
 	}
+	base = configuration
+	base.resolveToken()
+
+	if profile == "" {
+		profile = os.Getenv("TFDR_PROFILE")
+	}
+	if profile == "" {
+		return
+	}
+	if c, err := GetProfile(profile); err == nil {
+		configuration = c
+	} else {
+		configuration = base.merge(&Configuration{})
+		configuration.Profile = profile
+	}
+}
+
+// GenerateOptions are the settings of a generated config file. Missing settings are prompted for
+// when the config is generated interactively.
+type GenerateOptions struct {
+	Token   string
+	OrgName string
+	Address string
+	// Profile adds or updates only the named profile of the config file
+	Profile string
+	// Force overwrites an existing config file without asking
+	Force bool
+	// Interactive allows prompting for missing settings and for overwriting an existing file
+	Interactive bool
 }
 
-// GenerateConfig &
-func GenerateConfig(r io.Reader) {
-	c := promptConfig(r)
+// ErrNotInteractive &
+var ErrNotInteractive = errors.New("Terraform team token and org name are required when not running in a terminal. Use --token and --org")
+
+// GenerateConfig writes the config file that was read, or $HOME/.tfdr/config.yaml if none was found
+func GenerateConfig(r io.Reader, opts GenerateOptions) error {
+	c := Configuration{
+		TerraformTeamToken: opts.Token,
+		TerraformOrgName:   opts.OrgName,
+		TerraformAddress:   opts.Address,
+	}
+	if c.TerraformTeamToken == "" || c.TerraformOrgName == "" {
+		if !opts.Interactive {
+			return ErrNotInteractive
+		}
+		promptConfig(r, &c)
+	}
+
+	cfgFile := ConfigFile()
+	if opts.Profile != "" {
+		contents, err := setProfile(cfgFile, opts.Profile, c)
+		if err != nil {
+			return fmt.Errorf("Unable to update profile %s. Error: %v", opts.Profile, err)
+		}
+		return file.Save(cfgFile, contents)
+	}
+
+	var confirm func(string) bool
+	if opts.Interactive {
+		confirm = file.ConfirmOverwrite
+	}
 	bytes, _ := yaml.Marshal(c)
-	file.Create(string(bytes))
+	return file.Create(cfgFile, string(bytes), opts.Force, confirm)
 }
 
-func promptConfig(r io.Reader) Configuration {
+// promptConfig asks for the settings missing from c
+func promptConfig(r io.Reader, c *Configuration) {
 	reader := bufio.NewReader(r)
-	fmt.Println("Enter Terraform team token: ")
-	tfToken, _ := reader.ReadString('\n')
-
-	fmt.Println("Enter Terraform org name: ")
-	tfOrgName, _ := reader.ReadString('\n')
+	if c.TerraformTeamToken == "" {
+		fmt.Println("Enter Terraform team token: ")
+		tfToken, _ := reader.ReadString('\n')
+		c.TerraformTeamToken = strings.TrimSpace(tfToken)
+	}
 
-	configuration := Configuration{
-		TerraformTeamToken: strings.TrimSpace(tfToken),
-		TerraformOrgName:   strings.TrimSpace(tfOrgName),
+	if c.TerraformOrgName == "" {
+		fmt.Println("Enter Terraform org name: ")
+		tfOrgName, _ := reader.ReadString('\n')
+		c.TerraformOrgName = strings.TrimSpace(tfOrgName)
 	}
-	return configuration
 }
@@ -8,9 +8,11 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"strings"
 	"sync"
 	"testing"
 
+	"github.com/mupuri/go-tfdr/internal/config/file"
 	"github.com/sirupsen/logrus"
 	vpr "github.com/spf13/viper"
 	"github.com/stretchr/testify/suite"
@@ -24,6 +26,11 @@ func (s *TestSuite) SetupTest() {
 	os.Unsetenv("TF_TEAM_TOKEN")
 	os.Unsetenv("TF_ORG_NAME")
 	os.Unsetenv("TF_STATE_COPY_LOG_LEVEL")
+	os.Unsetenv("TF_ADDRESS")
+	os.Unsetenv("TF_CA_CERT_FILE")
+	os.Unsetenv("TF_INSECURE_SKIP_VERIFY")
+	os.Unsetenv("TF_HTTP_PROXY")
+	os.Unsetenv("TFDR_PROFILE")
 	viper = vpr.New()
 }
 
@@ -36,12 +43,13 @@ func (s *TestSuite) TestValidateConfig() {
 		tftoken   string
 		tforgname string
 		errorType error
+		errString string
 		message   string
 	}{
-		{"", "", ErrTFTeamTokenRequired, "empty configuration should return ErrTFTeamTokenRequired"},
-		{"", "test", ErrTFTeamTokenRequired, "empty tf token should return ErrTFTeamTokenRequired"},
-		{"test", "", ErrTFOrgNameRequired, "empty tf org name should return ErrTFOrgNameRequired"},
-		{"test", "test", nil, "valid configuration should not return an error"},
+		{"", "", ErrTFTeamTokenRequired, "Terraform team token is required", "empty configuration should return ErrTFTeamTokenRequired"},
+		{"", "test", ErrTFTeamTokenRequired, "Terraform team token is required", "empty tf token should return ErrTFTeamTokenRequired"},
+		{"test", "", ErrTFOrgNameRequired, "Terraform org name is required", "empty tf org name should return ErrTFOrgNameRequired"},
+		{"test", "test", nil, "", "valid configuration should not return an error"},
 	}
 
 	for _, c := range cases {
@@ -50,12 +58,24 @@ func (s *TestSuite) TestValidateConfig() {
 		err := ValidateConfig()
 		if c.errorType != nil {
 			s.True(errors.Is(err, c.errorType), c.message)
+			s.EqualError(err, c.errString, c.message)
 		} else {
 			s.NoError(err, c.message)
 		}
 	}
 }
 
+func (s *TestSuite) TestOverride() {
+	c := &Configuration{TerraformTeamToken: "token", TerraformOrgName: "org", LogLevel: "debug"}
+
+	override := c.Override("tfe.example.com", "", "dr-token")
+	s.Equal("tfe.example.com", override.TerraformAddress, "address should be overridden")
+	s.Equal("org", override.TerraformOrgName, "empty org name should not override")
+	s.Equal("dr-token", override.TerraformTeamToken, "token should be overridden")
+	s.Equal("debug", override.LogLevel, "log level should be kept")
+	s.Equal("token", c.TerraformTeamToken, "original configuration should be unchanged")
+}
+
 func createTestFile(filepath, tftoken, tforgname, loglevel string) error {
 	content := `tf_team_token: "%s"
 tf_org_name: "%s"
@@ -109,6 +129,23 @@ func (s *TestSuite) TestInitConfigEnv() {
 	s.Equal("debug", configuration.LogLevel, "log level should be 'debug'")
 }
 
+func (s *TestSuite) TestInitConfigTFEEnv() {
+	cfgFile := "./config-tfe-env-test.yaml"
+	os.Create(cfgFile)
+	defer os.RemoveAll(cfgFile)
+	os.Setenv("TF_ADDRESS", "https://tfe.example.com")
+	os.Setenv("TF_CA_CERT_FILE", "/etc/ssl/ca.pem")
+	os.Setenv("TF_INSECURE_SKIP_VERIFY", "true")
+	os.Setenv("TF_HTTP_PROXY", "http://proxy:3128")
+
+	InitConfig(cfgFile)
+
+	s.Equal("https://tfe.example.com", configuration.TerraformAddress, "tf address should be 'https://tfe.example.com'")
+	s.Equal("/etc/ssl/ca.pem", configuration.CACertFile, "ca cert file should be '/etc/ssl/ca.pem'")
+	s.True(configuration.InsecureSkipVerify, "insecure skip verify should be true")
+	s.Equal("http://proxy:3128", configuration.HTTPProxy, "http proxy should be 'http://proxy:3128'")
+}
+
 func (s *TestSuite) TestInitConfigFileOverrides() {
 	cfgFile := "./config-override-test.yml"
 	os.Setenv("TF_TEAM_TOKEN", "env_team_token")
@@ -132,13 +169,204 @@ func (s *TestSuite) TestCreate() {
 	var in bytes.Buffer
 	in.Write([]byte("team_token\norg_name\n"))
 	out := readStdOut(func() {
-		GenerateConfig(&in)
+		GenerateConfig(&in, GenerateOptions{Interactive: true})
 	})
 	cfgFile := path.Join(dir, ".tfdr/config.yaml")
 	s.FileExists(cfgFile)
 	s.Contains(out, "\nSuccessfully configured terraform disaster recovery cli. Use `tfdr config get` to view your configuration.")
 }
 
+func (s *TestSuite) TestCreateNonInteractive() {
+	dir := "./fake-home"
+	os.Setenv("HOME", dir)
+	defer os.RemoveAll(dir)
+	cfgFile := path.Join(dir, ".tfdr/config.yaml")
+
+	cases := []struct {
+		opts      GenerateOptions
+		expected  string
+		errorType error
+		message   string
+	}{
+		{GenerateOptions{Token: "team_token"}, "", ErrNotInteractive,
+			"missing org name should return ErrNotInteractive"},
+		{GenerateOptions{Token: "team_token", OrgName: "org_name", Address: "tfe.example.com"},
+			"tf_team_token: team_token\ntf_org_name: org_name\ntf_state_copy_log_level: \"\"\ntf_address: tfe.example.com\n", nil,
+			"flags should be written without prompting"},
+		{GenerateOptions{Token: "new_token", OrgName: "new_org"},
+			"tf_team_token: team_token\ntf_org_name: org_name\ntf_state_copy_log_level: \"\"\ntf_address: tfe.example.com\n", file.ErrConfigExists,
+			"existing config should not be overwritten without force"},
+		{GenerateOptions{Token: "new_token", OrgName: "new_org", Force: true},
+			"tf_team_token: new_token\ntf_org_name: new_org\ntf_state_copy_log_level: \"\"\n", nil,
+			"existing config should be overwritten with force"},
+		{GenerateOptions{Token: "dr_token", OrgName: "dr_org", Profile: "dr"},
+			"tf_team_token: new_token\ntf_org_name: new_org\ntf_state_copy_log_level: \"\"\nprofiles:\n  dr:\n    tf_team_token: dr_token\n    tf_org_name: dr_org\n", nil,
+			"profile should be added without force"},
+	}
+
+	for _, c := range cases {
+		var err error
+		readStdOut(func() {
+			err = GenerateConfig(&bytes.Buffer{}, c.opts)
+		})
+		if c.errorType != nil {
+			s.True(errors.Is(err, c.errorType), c.message)
+		} else {
+			s.NoError(err, c.message)
+		}
+		if c.expected == "" {
+			s.NoFileExists(cfgFile, c.message)
+			continue
+		}
+		contents, err := ioutil.ReadFile(cfgFile)
+		s.NoError(err, c.message)
+		s.Equal(c.expected, string(contents), c.message)
+		info, err := os.Stat(cfgFile)
+		s.NoError(err, c.message)
+		s.Equal(os.FileMode(0600), info.Mode().Perm(), c.message)
+	}
+}
+
+func (s *TestSuite) TestCreateConfigFlag() {
+	dir := "./fake-home"
+	os.Setenv("HOME", dir)
+	defer os.RemoveAll(dir)
+	cfgFile := "./config-new-test.yml"
+	s.NoError(ioutil.WriteFile(cfgFile, []byte(profilesConfig), 0600))
+	defer os.RemoveAll(cfgFile)
+	InitConfigWithProfile(cfgFile, "sandbox")
+
+	readStdOut(func() {
+		s.NoError(GenerateConfig(&bytes.Buffer{}, GenerateOptions{Token: "sb_token", OrgName: "sb_org", Profile: GetConfig().Profile}))
+	})
+	contents, err := ioutil.ReadFile(cfgFile)
+	s.NoError(err)
+	s.Equal(profilesConfig+"  sandbox:\n    tf_team_token: sb_token\n    tf_org_name: sb_org\n", string(contents), "profile should be added to the --config file")
+	s.NoFileExists(path.Join(dir, ".tfdr/config.yaml"), "config file in $HOME should not be written")
+
+	readStdOut(func() {
+		s.NoError(GenerateConfig(&bytes.Buffer{}, GenerateOptions{Token: "new_token", OrgName: "new_org", Force: true}))
+	})
+	contents, err = ioutil.ReadFile(cfgFile)
+	s.NoError(err)
+	s.Equal("tf_team_token: new_token\ntf_org_name: new_org\ntf_state_copy_log_level: \"\"\n", string(contents), "--config file should be overwritten")
+	s.NoFileExists(path.Join(dir, ".tfdr/config.yaml"), "config file in $HOME should not be written")
+}
+
+const profilesConfig = `tf_team_token: top_token
+tf_org_name: top_org
+tf_state_copy_log_level: info
+profiles:
+  prod:
+    tf_org_name: prod_org
+  dr:
+    tf_team_token: dr_token
+    tf_org_name: dr_org
+    tf_address: https://tfe.example.com
+`
+
+func (s *TestSuite) TestInitConfigProfiles() {
+	dir, _ := ioutil.TempDir("", "tfdr-test")
+	os.Setenv("HOME", dir)
+	defer os.RemoveAll(dir)
+	cfgFile := "./config-profiles-test.yml"
+	standby := "  standby:\n    tf_org_name: standby_org\n    tf_address: https://tfe.standby.example.com\n"
+	s.NoError(ioutil.WriteFile(cfgFile, []byte(profilesConfig+standby), 0644))
+	defer os.RemoveAll(cfgFile)
+
+	cases := []struct {
+		profile    string
+		envProfile string
+		envToken   string
+		expected   Configuration
+		errorType  error
+		message    string
+	}{
+		{"", "", "", Configuration{TokenSource: TokenSourceConfig, TerraformTeamToken: "top_token", TerraformOrgName: "top_org", LogLevel: "info"}, nil,
+			"no profile should use top level settings"},
+		{"dr", "", "", Configuration{TokenSource: TokenSourceConfig, TerraformTeamToken: "dr_token", TerraformOrgName: "dr_org", LogLevel: "info", TerraformAddress: "https://tfe.example.com", Profile: "dr"}, nil,
+			"dr profile should override top level settings"},
+		{"", "prod", "", Configuration{TokenSource: TokenSourceConfig, TerraformTeamToken: "top_token", TerraformOrgName: "prod_org", LogLevel: "info", Profile: "prod"}, nil,
+			"TFDR_PROFILE should select the prod profile"},
+		{"dr", "prod", "", Configuration{TokenSource: TokenSourceConfig, TerraformTeamToken: "dr_token", TerraformOrgName: "dr_org", LogLevel: "info", TerraformAddress: "https://tfe.example.com", Profile: "dr"}, nil,
+			"profile flag should take precedence over TFDR_PROFILE"},
+		{"prod", "", "env_token", Configuration{TokenSource: TokenSourceConfig, TerraformTeamToken: "env_token", TerraformOrgName: "prod_org", LogLevel: "info", Profile: "prod"}, nil,
+			"env token should be used when the profile does not set a token"},
+		{"dr", "", "env_token", Configuration{TokenSource: TokenSourceConfig, TerraformTeamToken: "dr_token", TerraformOrgName: "dr_org", LogLevel: "info", TerraformAddress: "https://tfe.example.com", Profile: "dr"}, nil,
+			"profile token should take precedence over env token"},
+		{"standby", "", "", Configuration{TerraformOrgName: "standby_org", LogLevel: "info", TerraformAddress: "https://tfe.standby.example.com", Profile: "standby"}, ErrTFTeamTokenRequired,
+			"profile with another host should not use the top level token"},
+		{"sandbox", "", "", Configuration{TokenSource: TokenSourceConfig, TerraformTeamToken: "top_token", TerraformOrgName: "top_org", LogLevel: "info", Profile: "sandbox"}, ErrProfileNotFound,
+			"missing profile should return ErrProfileNotFound"},
+	}
+
+	for _, c := range cases {
+		viper = vpr.New()
+		os.Setenv("TFDR_PROFILE", c.envProfile)
+		os.Setenv("TF_TEAM_TOKEN", c.envToken)
+		if c.envToken == "" {
+			os.Unsetenv("TF_TEAM_TOKEN")
+		}
+		InitConfigWithProfile(cfgFile, c.profile)
+
+		actual := *GetConfig()
+		actual.Profiles = nil
+		s.Equal(c.expected, actual, c.message)
+		err := ValidateConfig()
+		if c.errorType != nil {
+			s.True(errors.Is(err, c.errorType), c.message)
+		} else {
+			s.NoError(err, c.message)
+		}
+	}
+	os.Unsetenv("TF_TEAM_TOKEN")
+}
+
+func (s *TestSuite) TestGetProfile() {
+	cfgFile := "./config-get-profile-test.yml"
+	s.NoError(ioutil.WriteFile(cfgFile, []byte(profilesConfig), 0644))
+	defer os.RemoveAll(cfgFile)
+	InitConfigWithProfile(cfgFile, "prod")
+
+	c, err := GetProfile("dr")
+	s.NoError(err)
+	s.Equal("dr_org", c.TerraformOrgName, "dr profile should not include prod settings")
+	s.Equal("dr_token", c.TerraformTeamToken)
+	s.Equal("prod_org", GetConfig().TerraformOrgName, "active profile should be unchanged")
+
+	_, err = GetProfile("sandbox")
+	s.True(errors.Is(err, ErrProfileNotFound))
+}
+
+func (s *TestSuite) TestSetProfile() {
+	cfgFile := "./config-set-profile-test.yml"
+	s.NoError(ioutil.WriteFile(cfgFile, []byte(profilesConfig), 0644))
+	defer os.RemoveAll(cfgFile)
+
+	contents, err := setProfile(cfgFile, "prod", Configuration{TerraformTeamToken: "new_token", TerraformOrgName: "new_org"})
+	s.NoError(err)
+	s.Equal(`tf_team_token: top_token
+tf_org_name: top_org
+tf_state_copy_log_level: info
+profiles:
+  prod:
+    tf_team_token: new_token
+    tf_org_name: new_org
+  dr:
+    tf_team_token: dr_token
+    tf_org_name: dr_org
+    tf_address: https://tfe.example.com
+`, contents, "existing profile should be replaced in place")
+
+	contents, err = setProfile(cfgFile, "Sandbox", Configuration{TerraformTeamToken: "sb_token", TerraformOrgName: "sb_org"})
+	s.NoError(err)
+	s.True(strings.HasSuffix(contents, "  sandbox:\n    tf_team_token: sb_token\n    tf_org_name: sb_org\n"), "new profile should be appended")
+
+	contents, err = setProfile("./missing-config.yml", "dr", Configuration{TerraformTeamToken: "dr_token", TerraformOrgName: "dr_org"})
+	s.NoError(err)
+	s.Equal("profiles:\n  dr:\n    tf_team_token: dr_token\n    tf_org_name: dr_org\n", contents, "missing config file should only contain the profile")
+}
+
 func readStdOut(f func()) string {
 	r, w, _ := os.Pipe()
 	stdout := os.Stdout
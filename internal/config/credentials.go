@@ -0,0 +1,218 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+)
+
+// defaultHost is the Terraform Cloud host used when tf_address is not set
+const defaultHost = "app.terraform.io"
+
+// TokenSourceConfig is the token source of tokens set in the tfdr config file, its environment variables or flags
+const TokenSourceConfig = "tfdr config"
+
+// cliConfig is the part of the Terraform CLI config file that holds credentials
+type cliConfig struct {
+	Credentials        map[string]map[string]interface{} `hcl:"credentials"`
+	CredentialsHelpers map[string]*cliCredentialsHelper  `hcl:"credentials_helper"`
+}
+
+type cliCredentialsHelper struct {
+	Args []string `hcl:"args"`
+}
+
+// Host returns the hostname of the Terraform Cloud or Enterprise address of the configuration
+func (c *Configuration) Host() string {
+	if c.TerraformAddress == "" {
+		return defaultHost
+	}
+	address := c.TerraformAddress
+	if !strings.Contains(address, "://") {
+		address = "https://" + address
+	}
+	u, err := url.Parse(address)
+	if err != nil || u.Host == "" {
+		return c.TerraformAddress
+	}
+	return strings.ToLower(u.Host)
+}
+
+// resolveToken fills in an empty team token from the Terraform CLI credentials for the host of the
+// configuration. Sources are tried in the order terraform uses them: TF_TOKEN_<host> environment
+// variables, credentials blocks in the CLI config file, credentials.tfrc.json, and finally a
+// credentials helper.
+func (c *Configuration) resolveToken() {
+	c.credentialsErr = nil
+	if c.TerraformTeamToken != "" {
+		if c.TokenSource == "" {
+			c.TokenSource = TokenSourceConfig
+		}
+		return
+	}
+	c.TokenSource = ""
+	host := c.Host()
+
+	envName := hostTokenEnv(host)
+	if token := os.Getenv(envName); token != "" {
+		c.TerraformTeamToken, c.TokenSource = token, "env "+envName
+		return
+	}
+
+	cliConfigFile, cli, err := readCLIConfig()
+	if err != nil {
+		c.credentialsErr = err
+		return
+	}
+	if token, ok := cli.Credentials[host]["token"].(string); ok && token != "" {
+		c.TerraformTeamToken, c.TokenSource = token, "credentials block in "+cliConfigFile
+		return
+	}
+
+	credentialsFile := filepath.Join(terraformDir(), "credentials.tfrc.json")
+	token, err := readCredentialsFile(credentialsFile, host)
+	if err != nil {
+		c.credentialsErr = err
+		return
+	}
+	if token != "" {
+		c.TerraformTeamToken, c.TokenSource = token, credentialsFile
+		return
+	}
+
+	command, args := c.CredentialsHelper, []string{}
+	if command == "" {
+		for name, helper := range cli.CredentialsHelpers {
+			command = filepath.Join(terraformDir(), "plugins", "terraform-credentials-"+name)
+			if helper != nil {
+				args = helper.Args
+			}
+		}
+	} else {
+		fields := strings.Fields(command)
+		command, args = fields[0], fields[1:]
+	}
+	if command == "" {
+		return
+	}
+	token, err = runCredentialsHelper(command, args, host)
+	if err != nil {
+		c.credentialsErr = err
+		return
+	}
+	if token != "" {
+		c.TerraformTeamToken, c.TokenSource = token, "credentials helper "+filepath.Base(command)
+	}
+}
+
+// hostTokenEnv returns the TF_TOKEN_ environment variable terraform reads the token of host from
+func hostTokenEnv(host string) string {
+	return "TF_TOKEN_" + strings.NewReplacer(".", "_", "-", "__").Replace(host)
+}
+
+// terraformDir returns the directory terraform keeps credentials.tfrc.json and plugins in
+func terraformDir() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "terraform.d")
+	}
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".terraform.d")
+}
+
+// readCLIConfig reads the Terraform CLI config file from TF_CLI_CONFIG_FILE or its default location.
+// A missing file is not an error.
+func readCLIConfig() (string, *cliConfig, error) {
+	cliConfigFile := os.Getenv("TF_CLI_CONFIG_FILE")
+	if cliConfigFile == "" {
+		if runtime.GOOS == "windows" {
+			cliConfigFile = filepath.Join(os.Getenv("APPDATA"), "terraform.rc")
+		} else {
+			homeDir, _ := os.UserHomeDir()
+			cliConfigFile = filepath.Join(homeDir, ".terraformrc")
+		}
+	}
+
+	var cli cliConfig
+	contents, err := ioutil.ReadFile(cliConfigFile)
+	if os.IsNotExist(err) {
+		return cliConfigFile, &cli, nil
+	}
+	if err != nil {
+		return cliConfigFile, nil, fmt.Errorf("Unable to read terraform CLI config %s. Err: %v", cliConfigFile, err)
+	}
+	if err := hcl.Unmarshal(contents, &cli); err != nil {
+		return cliConfigFile, nil, fmt.Errorf("Unable to parse terraform CLI config %s. Err: %v", cliConfigFile, err)
+	}
+	return cliConfigFile, &cli, nil
+}
+
+// readCredentialsFile returns the token of host in a credentials.tfrc.json file, or an empty token if
+// the file does not exist or has no credentials for host
+func readCredentialsFile(credentialsFile string, host string) (string, error) {
+	contents, err := ioutil.ReadFile(credentialsFile)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("Unable to read terraform credentials %s. Err: %v", credentialsFile, err)
+	}
+
+	var credentials struct {
+		Credentials map[string]struct {
+			Token string `json:"token"`
+		} `json:"credentials"`
+	}
+	if err := json.Unmarshal(contents, &credentials); err != nil {
+		return "", fmt.Errorf("Unable to parse terraform credentials %s. Err: %v", credentialsFile, err)
+	}
+	return credentials.Credentials[host].Token, nil
+}
+
+// runCredentialsHelper runs `<command> <args> get <host>` following the terraform credentials helper
+// protocol and returns the token it prints
+func runCredentialsHelper(command string, args []string, host string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(command, append(args, "get", host)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("Credentials helper %s failed. Err: %v %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var credentials struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &credentials); err != nil {
+		return "", fmt.Errorf("Unable to parse output of credentials helper %s. Err: %v", command, err)
+	}
+	return credentials.Token, nil
+}
+
+// Masked returns a copy of the configuration with the team token and the proxy password masked
+func (c Configuration) Masked() Configuration {
+	c.TerraformTeamToken = MaskSecret(c.TerraformTeamToken)
+	if proxy, err := url.Parse(c.HTTPProxy); err == nil && proxy.User != nil {
+		if password, ok := proxy.User.Password(); ok {
+			proxy.User = url.UserPassword(proxy.User.Username(), MaskSecret(password))
+			c.HTTPProxy = proxy.String()
+		}
+	}
+	return c
+}
+
+// MaskSecret hides all but the last four characters of secret
+func MaskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return strings.Repeat("*", 8) + secret[len(secret)-4:]
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const credentialsHome = "./test-credentials-home"
+
+type CredentialsSuite struct {
+	suite.Suite
+	home string
+}
+
+func (s *CredentialsSuite) SetupTest() {
+	s.home = os.Getenv("HOME")
+	dir, _ := filepath.Abs(credentialsHome)
+	os.Setenv("HOME", dir)
+	os.MkdirAll(filepath.Join(dir, ".terraform.d", "plugins"), 0755)
+	os.Unsetenv("TF_TEAM_TOKEN")
+	os.Unsetenv("TF_CLI_CONFIG_FILE")
+	os.Unsetenv("TF_TOKEN_app_terraform_io")
+	os.Unsetenv("TF_TOKEN_tfe_example__corp_com")
+}
+
+func (s *CredentialsSuite) TearDownTest() {
+	os.Setenv("HOME", s.home)
+	os.RemoveAll(credentialsHome)
+	os.Unsetenv("TF_TOKEN_app_terraform_io")
+	os.Unsetenv("TF_TOKEN_tfe_example__corp_com")
+}
+
+func (s *CredentialsSuite) writeFile(name string, contents string, perm os.FileMode) string {
+	path := filepath.Join(os.Getenv("HOME"), name)
+	s.Require().NoError(ioutil.WriteFile(path, []byte(contents), perm))
+	return path
+}
+
+func (s *CredentialsSuite) TestHost() {
+	cases := []struct {
+		address  string
+		expected string
+	}{
+		{"", "app.terraform.io"},
+		{"tfe.example.com", "tfe.example.com"},
+		{"https://TFE.example.com/", "tfe.example.com"},
+		{"https://tfe.example.com:8443", "tfe.example.com:8443"},
+	}
+
+	for _, c := range cases {
+		s.Equal(c.expected, (&Configuration{TerraformAddress: c.address}).Host(), c.address)
+	}
+}
+
+func (s *CredentialsSuite) TestResolveToken() {
+	cases := []struct {
+		setup          func()
+		configuration  Configuration
+		expectedToken  string
+		expectedSource string
+		errorType      error
+		message        string
+	}{
+		{
+			setup:          func() {},
+			configuration:  Configuration{TerraformTeamToken: "config_token"},
+			expectedToken:  "config_token",
+			expectedSource: TokenSourceConfig,
+			message:        "configured token should be kept",
+		},
+		{
+			setup: func() {
+				s.writeFile(".terraform.d/credentials.tfrc.json", `{"credentials": {"app.terraform.io": {"token": "file_token"}}}`, 0600)
+			},
+			expectedToken:  "file_token",
+			expectedSource: filepath.Join(os.Getenv("HOME"), ".terraform.d", "credentials.tfrc.json"),
+			message:        "token should be read from credentials.tfrc.json",
+		},
+		{
+			setup: func() {
+				s.writeFile(".terraform.d/credentials.tfrc.json", `{"credentials": {"app.terraform.io": {"token": "file_token"}}}`, 0600)
+			},
+			configuration: Configuration{TerraformAddress: "https://tfe.example.com"},
+			errorType:     ErrTFTeamTokenRequired,
+			message:       "credentials of other hosts should not be used",
+		},
+		{
+			setup: func() {
+				s.writeFile(".terraform.d/credentials.tfrc.json", `{"credentials": {"app.terraform.io": {"token": "file_token"}}}`, 0600)
+				s.writeFile(".terraformrc", `credentials "app.terraform.io" {
+  token = "block_token"
+}`, 0600)
+			},
+			expectedToken:  "block_token",
+			expectedSource: "credentials block in " + filepath.Join(os.Getenv("HOME"), ".terraformrc"),
+			message:        "credentials block should take precedence over credentials.tfrc.json",
+		},
+		{
+			setup: func() {
+				os.Setenv("TF_CLI_CONFIG_FILE", s.writeFile("custom.tfrc", `credentials "tfe.example-corp.com" {
+  token = "custom_token"
+}`, 0600))
+			},
+			configuration:  Configuration{TerraformAddress: "tfe.example-corp.com"},
+			expectedToken:  "custom_token",
+			expectedSource: "credentials block in " + filepath.Join(os.Getenv("HOME"), "custom.tfrc"),
+			message:        "TF_CLI_CONFIG_FILE should be read",
+		},
+		{
+			setup: func() {
+				os.Setenv("TF_TOKEN_tfe_example__corp_com", "env_token")
+				s.writeFile(".terraformrc", `credentials "tfe.example-corp.com" {
+  token = "block_token"
+}`, 0600)
+			},
+			configuration:  Configuration{TerraformAddress: "tfe.example-corp.com"},
+			expectedToken:  "env_token",
+			expectedSource: "env TF_TOKEN_tfe_example__corp_com",
+			message:        "TF_TOKEN_ env variable should take precedence over credentials blocks",
+		},
+		{
+			setup: func() {
+				s.writeFile(".terraformrc", `credentials_helper "test" {
+  args = ["--prefix", "helper"]
+}`, 0600)
+				s.writeFile(".terraform.d/plugins/terraform-credentials-test", "#!/bin/sh\necho \"{\\\"token\\\": \\\"$2_$4\\\"}\"\n", 0755)
+			},
+			expectedToken:  "helper_app.terraform.io",
+			expectedSource: "credentials helper terraform-credentials-test",
+			message:        "token should be read from the CLI config credentials helper",
+		},
+		{
+			setup: func() {
+				s.writeFile("helper.sh", "#!/bin/sh\necho '{\"token\": \"tfdr_helper_token\"}'\n", 0755)
+			},
+			configuration:  Configuration{CredentialsHelper: filepath.Join(os.Getenv("HOME"), "helper.sh")},
+			expectedToken:  "tfdr_helper_token",
+			expectedSource: "credentials helper helper.sh",
+			message:        "token should be read from the configured credentials helper",
+		},
+		{
+			setup: func() {
+				s.writeFile("helper.sh", "#!/bin/sh\necho 'no credentials' >&2\nexit 1\n", 0755)
+			},
+			configuration: Configuration{CredentialsHelper: filepath.Join(os.Getenv("HOME"), "helper.sh")},
+			errorType:     ErrTFTeamTokenRequired,
+			message:       "failing credentials helper should return ErrTFTeamTokenRequired",
+		},
+		{
+			setup: func() {
+				s.writeFile(".terraform.d/credentials.tfrc.json", `{"credentials": `, 0600)
+			},
+			errorType: ErrTFTeamTokenRequired,
+			message:   "invalid credentials.tfrc.json should return ErrTFTeamTokenRequired",
+		},
+	}
+
+	for _, c := range cases {
+		s.TearDownTest()
+		s.SetupTest()
+		c.setup()
+		c.configuration.TerraformOrgName = "org"
+		c.configuration.resolveToken()
+
+		s.Equal(c.expectedToken, c.configuration.TerraformTeamToken, c.message)
+		s.Equal(c.expectedSource, c.configuration.TokenSource, c.message)
+		err := c.configuration.Validate()
+		if c.errorType != nil {
+			s.True(errors.Is(err, c.errorType), c.message)
+		} else {
+			s.NoError(err, c.message)
+		}
+	}
+}
+
+func (s *CredentialsSuite) TestOverrideResolvesTokenForNewHost() {
+	s.writeFile(".terraform.d/credentials.tfrc.json", `{"credentials": {
+  "app.terraform.io": {"token": "tfc_token"},
+  "tfe.example.com": {"token": "tfe_token"}
+}}`, 0600)
+	c := &Configuration{TerraformOrgName: "org"}
+	c.resolveToken()
+	s.Equal("tfc_token", c.TerraformTeamToken)
+
+	s.Equal("tfe_token", c.Override("tfe.example.com", "", "").TerraformTeamToken, "token should be looked up for the new host")
+	s.Equal("flag_token", c.Override("tfe.example.com", "", "flag_token").TerraformTeamToken, "token flag should take precedence")
+
+	configured := &Configuration{TerraformTeamToken: "config_token", TerraformOrgName: "org"}
+	configured.resolveToken()
+	s.Equal("config_token", configured.Override("https://app.terraform.io", "", "").TerraformTeamToken, "configured token should be kept for the same host")
+	s.Equal("tfe_token", configured.Override("tfe.example.com", "", "").TerraformTeamToken, "configured token should not be sent to another host")
+
+	unknown := configured.Override("tfe.dr.example.com", "", "")
+	s.Empty(unknown.TerraformTeamToken, "configured token should not be sent to an unknown host")
+	s.True(errors.Is(unknown.Validate(), ErrTFTeamTokenRequired), "unknown host without a token should be rejected")
+}
+
+func (s *CredentialsSuite) TestMaskSecret() {
+	s.Equal("", MaskSecret(""))
+	s.Equal("***", MaskSecret("abc"))
+	s.Equal("********ghij", MaskSecret("abcdefghij"))
+}
+
+func TestCredentialsSuite(t *testing.T) {
+	suite.Run(t, new(CredentialsSuite))
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mupuri/go-tfdr/internal/config/file"
+	"gopkg.in/yaml.v2"
+)
+
+// ErrUnknownConfigKey &
+var ErrUnknownConfigKey = errors.New("Unknown config key")
+
+// Keys returns the keys that can be set in the config file or a profile
+func Keys() []string {
+	keys := make([]string, 0)
+	t := reflect.TypeOf(Configuration{})
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("mapstructure")
+		if key != "" && key != "-" && key != "profiles" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ConfigFile returns the config file that was read, or $HOME/.tfdr/config.yaml if none was found
+func ConfigFile() string {
+	if cfgFile := viper.ConfigFileUsed(); cfgFile != "" {
+		return cfgFile
+	}
+	return file.Path()
+}
+
+// SetValue sets key to value in the config file, or in the active profile if there is one, and
+// returns the path of the file it wrote. The rest of the file is kept as it is.
+func SetValue(key string, value string) (string, error) {
+	kind, ok := keyKind(key)
+	if !ok {
+		return "", fmt.Errorf("%w %q. Valid keys are %s", ErrUnknownConfigKey, key, strings.Join(Keys(), ", "))
+	}
+	var typed interface{} = value
+	if kind == reflect.Bool {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("Invalid value %q for %s. Expected true or false", value, key)
+		}
+		typed = b
+	}
+
+	cfgFile := ConfigFile()
+	contents, err := readConfigFile(cfgFile)
+	if err != nil {
+		return "", err
+	}
+	if profile := strings.ToLower(configuration.Profile); profile != "" {
+		profiles := getMapSlice(contents, "profiles")
+		profiles = setMapItem(profiles, profile, setMapItem(getMapSlice(profiles, profile), key, typed))
+		contents = setMapItem(contents, "profiles", profiles)
+	} else {
+		contents = setMapItem(contents, key, typed)
+	}
+
+	if err := writeConfigFile(cfgFile, contents); err != nil {
+		return "", err
+	}
+	return cfgFile, nil
+}
+
+func keyKind(key string) (reflect.Kind, bool) {
+	t := reflect.TypeOf(Configuration{})
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("mapstructure") == key && key != "-" && key != "profiles" {
+			return t.Field(i).Type.Kind(), true
+		}
+	}
+	return reflect.Invalid, false
+}
+
+// readConfigFile reads the config file keeping the order of its keys. A missing file is empty.
+func readConfigFile(cfgFile string) (yaml.MapSlice, error) {
+	var contents yaml.MapSlice
+	bytes, err := ioutil.ReadFile(cfgFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(bytes, &contents); err != nil {
+		return nil, fmt.Errorf("Unable to parse config file %s. Err: %v", cfgFile, err)
+	}
+	return contents, nil
+}
+
+func writeConfigFile(cfgFile string, contents yaml.MapSlice) error {
+	bytes, err := yaml.Marshal(contents)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cfgFile), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(cfgFile, bytes, 0600); err != nil {
+		return err
+	}
+	// the file can hold tokens, so tighten the permissions of existing files as well
+	return os.Chmod(cfgFile, 0600)
+}
+
+// setProfile returns the contents of the config file at cfgFile with the named profile set to c.
+// Other settings and profiles are kept in the order they appear in the file.
+func setProfile(cfgFile string, profile string, c Configuration) (string, error) {
+	contents, err := readConfigFile(cfgFile)
+	if err != nil {
+		return "", err
+	}
+	settings, err := profileSettings(c)
+	if err != nil {
+		return "", err
+	}
+
+	profiles := setMapItem(getMapSlice(contents, "profiles"), strings.ToLower(profile), settings)
+	contents = setMapItem(contents, "profiles", profiles)
+
+	bytes, err := yaml.Marshal(contents)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+// profileSettings returns the non empty settings of c
+func profileSettings(c Configuration) (yaml.MapSlice, error) {
+	bytes, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var all yaml.MapSlice
+	if err := yaml.Unmarshal(bytes, &all); err != nil {
+		return nil, err
+	}
+	settings := yaml.MapSlice{}
+	for _, item := range all {
+		if item.Value != "" {
+			settings = append(settings, item)
+		}
+	}
+	return settings, nil
+}
+
+func setMapItem(items yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	for i, item := range items {
+		if item.Key == key {
+			items[i].Value = value
+			return items
+		}
+	}
+	return append(items, yaml.MapItem{Key: key, Value: value})
+}
+
+// getMapSlice returns the map under key, or an empty map if there is none
+func getMapSlice(items yaml.MapSlice, key string) yaml.MapSlice {
+	for _, item := range items {
+		if item.Key == key {
+			if value, ok := item.Value.(yaml.MapSlice); ok {
+				return value
+			}
+		}
+	}
+	return yaml.MapSlice{}
+}
@@ -1,52 +1,86 @@
 package file
 
 import (
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"path/filepath"
 
 	"github.com/eiannone/keyboard"
+	"golang.org/x/term"
 )
 
-func Create(contents string) {
+// ErrConfigExists &
+var ErrConfigExists = errors.New("Config file already exists")
+
+// Path returns the path of the config file in $HOME/.tfdr
+func Path() string {
 	homeDir, _ := os.UserHomeDir()
-	configDir := filepath.Join(homeDir, ".tfdr")
-	fileName := "config.yaml"
-	if _, err := os.Stat(configDir); err != nil {
-		if os.IsNotExist(err) {
-			if err := os.MkdirAll(configDir, 0755); err != nil {
-				log.Fatalf("Unable to create config directiory in path: %s. Error: %s", configDir, err.Error())
-			}
-		}
+	return filepath.Join(homeDir, ".tfdr", "config.yaml")
+}
+
+// IsTerminal returns true if f is connected to a terminal
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// ConfirmOverwrite asks on the terminal whether to overwrite cfgFile
+func ConfirmOverwrite(cfgFile string) bool {
+	fmt.Printf("Config file (%s) found, Overwrite? [Y/n] ", cfgFile)
+	txt, key, _ := keyboard.GetSingleKey()
+	return key == keyboard.KeyEnter || txt == 'Y' || txt == 'y'
+}
+
+// Create writes the config file cfgFile. An existing file is overwritten if force is set or confirm
+// returns true. Without force or confirm, an existing file returns ErrConfigExists.
+func Create(cfgFile string, contents string, force bool, confirm func(cfgFile string) bool) error {
+	if err := createConfigDir(cfgFile); err != nil {
+		return err
 	}
-	cfgFile := filepath.Join(configDir, fileName)
-	_, err := os.Stat(cfgFile)
-	if os.IsNotExist(err) {
-		saveConfig(cfgFile, contents)
-	} else {
-		// reader := bufio.NewReader(os.Stdin)
-		fmt.Printf("Config file (%s) found, Overwrite? [Y/n] ", cfgFile)
-		txt, key, _ := keyboard.GetSingleKey()
-		if key == keyboard.KeyEnter || txt == 'Y' || txt == 'y' {
-			saveConfig(cfgFile, contents)
+	if _, err := os.Stat(cfgFile); err == nil && !force {
+		if confirm == nil {
+			return fmt.Errorf("%w: %s. Use --force to overwrite it", ErrConfigExists, cfgFile)
+		}
+		if !confirm(cfgFile) {
+			return nil
 		}
 	}
+	return saveConfig(cfgFile, contents)
 }
 
-func saveConfig(cfgFile string, contents string) {
-	file, err := os.Create(cfgFile)
+// Save writes the config file cfgFile without asking to overwrite an existing file
+func Save(cfgFile string, contents string) error {
+	if err := createConfigDir(cfgFile); err != nil {
+		return err
+	}
+	return saveConfig(cfgFile, contents)
+}
+
+func createConfigDir(cfgFile string) error {
+	configDir := filepath.Dir(cfgFile)
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return fmt.Errorf("Unable to create config directiory in path: %s. Error: %v", configDir, err)
+	}
+	return nil
+}
+
+// saveConfig writes the config file readable by its owner only, since it holds the team token
+func saveConfig(cfgFile string, contents string) error {
+	file, err := os.OpenFile(cfgFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
 	if err != nil {
-		log.Fatalf("Error: failed while creating config file. Error: %s", err.Error())
+		return fmt.Errorf("Failed while creating config file. Error: %v", err)
 	}
 	defer file.Close()
+	if err := file.Chmod(0600); err != nil {
+		return fmt.Errorf("Failed while setting config file permissions. Error: %v", err)
+	}
 
-	_, err = io.WriteString(file, contents)
-	if err != nil {
-		log.Fatal("Error: failed while attempting to write config yaml")
+	if _, err = io.WriteString(file, contents); err != nil {
+		return fmt.Errorf("Failed while attempting to write config yaml. Error: %v", err)
 	}
 	_ = file.Sync()
 
 	fmt.Println("\nSuccessfully configured terraform disaster recovery cli. Use `tfdr config get` to view your configuration.")
+	return nil
 }
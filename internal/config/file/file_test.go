@@ -1,7 +1,8 @@
 package file
 
 import (
-	"bytes"
+	"errors"
+	"io/ioutil"
 	"os"
 	"path"
 	"testing"
@@ -14,8 +15,26 @@ func TestCreate(t *testing.T) {
 	os.MkdirAll(dir, 0755)
 	defer os.RemoveAll(dir)
 	os.Setenv("HOME", dir)
-	var in bytes.Buffer
-	in.Write([]byte("account_id\napi_key\nY\n"))
-	Create("hello, world")
-	assert.FileExists(t, path.Join(dir, ".tfdr/config.yaml"))
+	cfgFile := path.Join(dir, ".tfdr/config.yaml")
+
+	assert.NoError(t, Create(cfgFile, "hello, world", false, nil))
+	assert.FileExists(t, cfgFile)
+	info, err := os.Stat(cfgFile)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "config file should only be readable by its owner")
+
+	err = Create(cfgFile, "overwritten", false, nil)
+	assert.True(t, errors.Is(err, ErrConfigExists), "existing file should not be overwritten without confirmation")
+
+	assert.NoError(t, Create(cfgFile, "declined", false, func(string) bool { return false }))
+	contents, _ := ioutil.ReadFile(cfgFile)
+	assert.Equal(t, "hello, world", string(contents), "declined overwrite should keep the file")
+
+	os.Chmod(cfgFile, 0644)
+	assert.NoError(t, Create(cfgFile, "forced", true, nil))
+	contents, _ = ioutil.ReadFile(cfgFile)
+	assert.Equal(t, "forced", string(contents), "forced overwrite should replace the file")
+	info, err = os.Stat(cfgFile)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "overwritten config file should only be readable by its owner")
 }
@@ -0,0 +1,229 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mupuri/go-tfdr/internal/models"
+)
+
+// Rename &
+type Rename struct {
+	From string
+	To   string
+}
+
+// Change lists the attributes that differ between the before and after versions of a resource
+type Change struct {
+	Address    string
+	Attributes []string
+	// Provider is the new provider of the resource if it changed
+	Provider string
+}
+
+// Report describes what happens to each resource of a state when it is replaced by another
+type Report struct {
+	Added   []string
+	Removed []string
+	// Kept lists resources that are neither renamed nor changed
+	Kept    []string
+	Renamed []Rename
+	Changed []Change
+}
+
+// Resources compares the resources of a state before and after filtering. renames maps the
+// original address of each renamed resource to its new address.
+func Resources(before []models.Resource, after []models.Resource, renames map[string]string) *Report {
+	afterByAddress := make(map[string]models.Resource, len(after))
+	for _, r := range after {
+		afterByAddress[r.Address()] = r
+	}
+
+	report := &Report{}
+	seen := make(map[string]bool, len(after))
+	for _, b := range before {
+		address := b.Address()
+		if newAddress, ok := renames[address]; ok {
+			report.Renamed = append(report.Renamed, Rename{From: address, To: newAddress})
+			address = newAddress
+		}
+		a, ok := afterByAddress[address]
+		seen[address] = true
+		if !ok {
+			report.Removed = append(report.Removed, b.Address())
+			continue
+		}
+		_, renamed := renames[b.Address()]
+		provider := ""
+		if a.Provider != b.Provider {
+			provider = a.Provider
+		}
+		pairs, removed, added := matchInstances(b, a)
+		if len(removed) == 0 && len(added) == 0 {
+			report.compare(address, pairs, provider, renamed)
+			continue
+		}
+
+		// resources that lose or gain instances are reported instance by instance
+		for _, instance := range removed {
+			report.Removed = append(report.Removed, b.InstanceAddress(instance))
+		}
+		for _, instance := range added {
+			report.Added = append(report.Added, a.InstanceAddress(instance))
+		}
+		for _, pair := range pairs {
+			report.compare(a.InstanceAddress(pair[1]), [][2]models.Instance{pair}, provider, renamed)
+		}
+	}
+	for _, a := range after {
+		if !seen[a.Address()] {
+			report.Added = append(report.Added, a.Address())
+		}
+	}
+	return report
+}
+
+// compare adds address to the changed or kept resources depending on whether the attributes of the
+// before and after instances or the provider differ
+func (r *Report) compare(address string, pairs [][2]models.Instance, provider string, renamed bool) {
+	change := Change{Address: address, Attributes: changedAttributes(pairs), Provider: provider}
+	changed := len(change.Attributes) > 0 || change.Provider != ""
+	if changed {
+		r.Changed = append(r.Changed, change)
+	}
+	if !renamed && !changed {
+		r.Kept = append(r.Kept, address)
+	}
+}
+
+// Write prints the report in a human readable format
+func (r *Report) Write(w io.Writer) {
+	for _, address := range r.Added {
+		fmt.Fprintf(w, "  + %s\n", address)
+	}
+	for _, address := range r.Removed {
+		fmt.Fprintf(w, "  - %s\n", address)
+	}
+	for _, rename := range r.Renamed {
+		fmt.Fprintf(w, "  > %s -> %s\n", rename.From, rename.To)
+	}
+	for _, change := range r.Changed {
+		fmt.Fprintf(w, "  ~ %s", change.Address)
+		if len(change.Attributes) > 0 {
+			fmt.Fprintf(w, " %v", change.Attributes)
+		}
+		if change.Provider != "" {
+			fmt.Fprintf(w, " provider: %s", change.Provider)
+		}
+		fmt.Fprintln(w)
+	}
+	for _, address := range r.Kept {
+		fmt.Fprintf(w, "    %s\n", address)
+	}
+	fmt.Fprintf(w, "\n%d added, %d removed, %d renamed, %d changed, %d kept\n", len(r.Added), len(r.Removed), len(r.Renamed), len(r.Changed), len(r.Kept))
+}
+
+// Outputs compares the root module outputs of a state before and after filtering. renames maps the
+// original name of each renamed output to its new name.
+func Outputs(before map[string]models.Output, after map[string]models.Output, renames map[string]string) *Report {
+	report := &Report{}
+	seen := make(map[string]bool, len(after))
+	for _, name := range sortedOutputNames(before) {
+		newName := name
+		if n, ok := renames[name]; ok {
+			report.Renamed = append(report.Renamed, Rename{From: outputAddress(name), To: outputAddress(n)})
+			newName = n
+		}
+		seen[newName] = true
+		a, ok := after[newName]
+		if !ok {
+			report.Removed = append(report.Removed, outputAddress(name))
+			continue
+		}
+		changed := !reflect.DeepEqual(before[name], a)
+		if changed {
+			report.Changed = append(report.Changed, Change{Address: outputAddress(newName), Attributes: []string{"value"}})
+		}
+		if newName == name && !changed {
+			report.Kept = append(report.Kept, outputAddress(name))
+		}
+	}
+	for _, name := range sortedOutputNames(after) {
+		if !seen[name] {
+			report.Added = append(report.Added, outputAddress(name))
+		}
+	}
+	return report
+}
+
+func outputAddress(name string) string {
+	return "output." + name
+}
+
+func sortedOutputNames(outputs map[string]models.Output) []string {
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// matchInstances pairs the instances of before and after with the same index key. Instances of before
+// without a match are returned as removed, and instances of after without a match as added.
+func matchInstances(before models.Resource, after models.Resource) (pairs [][2]models.Instance, removed []models.Instance, added []models.Instance) {
+	afterByKey := make(map[string][]models.Instance, len(after.Instances))
+	for _, instance := range after.Instances {
+		key := indexKey(after, instance)
+		afterByKey[key] = append(afterByKey[key], instance)
+	}
+	for _, instance := range before.Instances {
+		key := indexKey(before, instance)
+		if matches := afterByKey[key]; len(matches) > 0 {
+			pairs = append(pairs, [2]models.Instance{instance, matches[0]})
+			afterByKey[key] = matches[1:]
+			continue
+		}
+		removed = append(removed, instance)
+	}
+	for _, instance := range after.Instances {
+		key := indexKey(after, instance)
+		if matches := afterByKey[key]; len(matches) > 0 {
+			added = append(added, matches[0])
+			afterByKey[key] = matches[1:]
+		}
+	}
+	return pairs, removed, added
+}
+
+// indexKey returns the index key of an instance as it appears in its address, e.g. [0] or ["east"]
+func indexKey(resource models.Resource, instance models.Instance) string {
+	return strings.TrimPrefix(resource.InstanceAddress(instance), resource.Address())
+}
+
+func changedAttributes(pairs [][2]models.Instance) []string {
+	changed := make(map[string]bool)
+	for _, pair := range pairs {
+		b, a := pair[0].Attributes, pair[1].Attributes
+		for k, v := range b {
+			if !reflect.DeepEqual(v, a[k]) {
+				changed[k] = true
+			}
+		}
+		for k, v := range a {
+			if _, ok := b[k]; !ok || !reflect.DeepEqual(v, b[k]) {
+				changed[k] = true
+			}
+		}
+	}
+
+	attrs := make([]string, 0, len(changed))
+	for k := range changed {
+		attrs = append(attrs, k)
+	}
+	sort.Strings(attrs)
+	return attrs
+}
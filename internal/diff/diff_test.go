@@ -0,0 +1,132 @@
+package diff
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mupuri/go-tfdr/internal/models"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSuite struct {
+	suite.Suite
+}
+
+func TestRunSuite(t *testing.T) {
+	suite.Run(t, new(TestSuite))
+}
+
+func newResource(module, mode, typ, name string, attrs map[string]interface{}) models.Resource {
+	return models.Resource{
+		Module: module,
+		Mode:   mode,
+		Type:   typ,
+		Name:   name,
+		Instances: []models.Instance{
+			{Attributes: attrs},
+		},
+	}
+}
+
+func (s *TestSuite) TestResources() {
+	before := []models.Resource{
+		newResource("module.a", "managed", "type_a", "removed", map[string]interface{}{"id": "1"}),
+		newResource("module.a", "managed", "type_a", "kept", map[string]interface{}{"id": "2"}),
+		newResource("", "managed", "type_b", "orig", map[string]interface{}{"id": "3"}),
+		newResource("", "data", "type_c", "changed", map[string]interface{}{"id": "4", "region": "us-east-1"}),
+	}
+	after := []models.Resource{
+		newResource("module.a", "managed", "type_a", "kept", map[string]interface{}{"id": "2"}),
+		newResource("", "managed", "type_b", "new", map[string]interface{}{"id": "3"}),
+		newResource("", "data", "type_c", "changed", map[string]interface{}{"id": "4", "region": "us-west-2"}),
+		newResource("", "managed", "type_d", "added", map[string]interface{}{"id": "5"}),
+	}
+	after[0].Provider = "provider.aws.dr"
+	renames := map[string]string{"type_b.orig": "type_b.new"}
+
+	report := Resources(before, after, renames)
+	s.Equal([]string{"type_d.added"}, report.Added)
+	s.Equal([]string{"module.a.type_a.removed"}, report.Removed)
+	s.Empty(report.Kept)
+	s.Equal([]Rename{{From: "type_b.orig", To: "type_b.new"}}, report.Renamed)
+	s.Equal([]Change{
+		{Address: "module.a.type_a.kept", Attributes: []string{}, Provider: "provider.aws.dr"},
+		{Address: "data.type_c.changed", Attributes: []string{"region"}},
+	}, report.Changed)
+
+	var buf bytes.Buffer
+	report.Write(&buf)
+	s.Contains(buf.String(), "  + type_d.added\n")
+	s.Contains(buf.String(), "  - module.a.type_a.removed\n")
+	s.Contains(buf.String(), "  > type_b.orig -> type_b.new\n")
+	s.Contains(buf.String(), "  ~ data.type_c.changed [region]\n")
+	s.Contains(buf.String(), "  ~ module.a.type_a.kept provider: provider.aws.dr\n")
+	s.Contains(buf.String(), "1 added, 1 removed, 1 renamed, 2 changed, 0 kept")
+}
+
+func (s *TestSuite) TestResourcesInstances() {
+	instances := func(attrs ...map[string]interface{}) []models.Instance {
+		result := make([]models.Instance, 0, len(attrs))
+		for _, a := range attrs {
+			result = append(result, models.Instance{IndexKey: a["key"], Attributes: a})
+		}
+		return result
+	}
+	before := []models.Resource{
+		{Mode: "managed", Type: "aws_instance", Name: "web", Instances: instances(
+			map[string]interface{}{"key": 0.0, "id": "i-0", "ami": "ami-1"},
+			map[string]interface{}{"key": 1.0, "id": "i-1", "ami": "ami-2"},
+		)},
+		{Mode: "managed", Type: "aws_s3_bucket", Name: "logs", Instances: instances(
+			map[string]interface{}{"key": "east", "id": "logs-east"},
+			map[string]interface{}{"key": "west", "id": "logs-west"},
+		)},
+		{Mode: "managed", Type: "aws_eip", Name: "ip", Instances: instances(
+			map[string]interface{}{"key": "a", "id": "eip-a"},
+			map[string]interface{}{"key": "b", "id": "eip-b"},
+		)},
+	}
+	after := []models.Resource{
+		{Mode: "managed", Type: "aws_instance", Name: "web", Instances: instances(
+			map[string]interface{}{"key": 1.0, "id": "i-1", "ami": "ami-2"},
+		)},
+		{Mode: "managed", Type: "aws_s3_bucket", Name: "logs", Instances: instances(
+			map[string]interface{}{"key": "west", "id": "logs-west-dr"},
+		)},
+		{Mode: "managed", Type: "aws_eip", Name: "ip", Instances: instances(
+			map[string]interface{}{"key": "b", "id": "eip-b"},
+			map[string]interface{}{"key": "a", "id": "eip-a"},
+		)},
+	}
+
+	report := Resources(before, after, nil)
+	s.Empty(report.Added)
+	s.Equal([]string{"aws_instance.web[0]", `aws_s3_bucket.logs["east"]`}, report.Removed)
+	s.Equal([]Change{{Address: `aws_s3_bucket.logs["west"]`, Attributes: []string{"id"}}}, report.Changed)
+	s.Equal([]string{"aws_instance.web[1]", "aws_eip.ip"}, report.Kept, "instances should be matched by index key, not position")
+
+	report = Resources(after, before, nil)
+	s.Equal([]string{"aws_instance.web[0]", `aws_s3_bucket.logs["east"]`}, report.Added)
+	s.Empty(report.Removed)
+}
+
+func (s *TestSuite) TestOutputs() {
+	before := map[string]models.Output{
+		"kept":    {Value: "a", Type: "string"},
+		"removed": {Value: "b", Type: "string"},
+		"orig":    {Value: "c", Type: "string"},
+		"changed": {Value: "d", Type: "string"},
+	}
+	after := map[string]models.Output{
+		"kept":    {Value: "a", Type: "string"},
+		"new":     {Value: "c", Type: "string"},
+		"changed": {Value: "e", Type: "string"},
+	}
+
+	report := Outputs(before, after, map[string]string{"orig": "new"})
+	s.Empty(report.Added)
+	s.Equal([]string{"output.removed"}, report.Removed)
+	s.Equal([]string{"output.kept"}, report.Kept)
+	s.Equal([]Rename{{From: "output.orig", To: "output.new"}}, report.Renamed)
+	s.Equal([]Change{{Address: "output.changed", Attributes: []string{"value"}}}, report.Changed)
+}
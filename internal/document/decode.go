@@ -0,0 +1,234 @@
+package document
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Decode stores the value of node in the value pointed to by v. Struct fields are matched by their
+// json tag and keys that do not match a field are rejected. Numbers are decoded into interface{}
+// values as float64, like encoding/json.
+func Decode(node *Node, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errorf(node.Pos, "decode target must be a non nil pointer")
+	}
+	return decode(node, rv.Elem())
+}
+
+func decode(node *Node, v reflect.Value) error {
+	if node.Kind == Null {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decode(node, v.Elem())
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(node.Interface()))
+		return nil
+	case reflect.Struct:
+		return decodeStruct(node, v)
+	case reflect.Map:
+		return decodeMap(node, v)
+	case reflect.Slice:
+		return decodeSlice(node, v)
+	case reflect.String:
+		s, ok := node.Value.(string)
+		if !ok {
+			return mismatch(node, "string")
+		}
+		v.SetString(s)
+		return nil
+	case reflect.Bool:
+		b, ok := node.Value.(bool)
+		if !ok {
+			return mismatch(node, "boolean")
+		}
+		v.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f, ok := node.Value.(float64)
+		if !ok || f != float64(int64(f)) {
+			return mismatch(node, "integer")
+		}
+		v.SetInt(int64(f))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, ok := node.Value.(float64)
+		if !ok {
+			return mismatch(node, "number")
+		}
+		v.SetFloat(f)
+		return nil
+	default:
+		return errorf(node.Pos, "unsupported type %s", v.Type())
+	}
+}
+
+func mismatch(node *Node, expected string) error {
+	found := node.Kind.String()
+	if node.Kind == Scalar {
+		switch node.Value.(type) {
+		case string:
+			found = "string"
+		case bool:
+			found = "boolean"
+		default:
+			found = "number"
+		}
+	}
+	return errorf(node.Pos, "expected %s but found %s", article(expected), article(found))
+}
+
+func article(noun string) string {
+	if strings.ContainsAny(noun[:1], "aeiou") {
+		return "an " + noun
+	}
+	return "a " + noun
+}
+
+// fields returns the fields of a struct type by their json name
+func fields(t reflect.Type) map[string]int {
+	fields := map[string]int{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = i
+	}
+	return fields
+}
+
+func decodeStruct(node *Node, v reflect.Value) error {
+	// a single repeated HCL block
+	if node.Kind == List && node.block && len(node.Items) == 1 {
+		node = node.Items[0]
+	}
+	if node.Kind != Map {
+		return mismatch(node, "object")
+	}
+
+	fields := fields(v.Type())
+	seen := map[string]bool{}
+	for _, entry := range node.Entries {
+		i, ok := fields[entry.Key]
+		if !ok {
+			return unknownKey(entry, fields)
+		}
+		if seen[entry.Key] {
+			return errorf(entry.KeyPos, "duplicate key %q", entry.Key)
+		}
+		seen[entry.Key] = true
+		if err := decode(entry.Value, v.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unknownKey(entry Entry, fields map[string]int) error {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	suggestion, best := "", len(entry.Key)/2+1
+	for _, name := range names {
+		if d := distance(entry.Key, name); d < best {
+			suggestion, best = name, d
+		}
+	}
+	if suggestion != "" {
+		return errorf(entry.KeyPos, "unknown key %q, did you mean %q?", entry.Key, suggestion)
+	}
+	return errorf(entry.KeyPos, "unknown key %q. Expected one of: %s", entry.Key, strings.Join(names, ", "))
+}
+
+// distance is the Levenshtein distance between a and b
+func distance(a, b string) int {
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			next := min(row[j]+1, row[j-1]+1, prev+cost)
+			prev, row[j] = row[j], next
+		}
+	}
+	return row[len(b)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+func decodeMap(node *Node, v reflect.Value) error {
+	if node.Kind != Map {
+		return mismatch(node, "object")
+	}
+	if v.Type().Key().Kind() != reflect.String {
+		return errorf(node.Pos, "unsupported type %s", v.Type())
+	}
+	m := reflect.MakeMapWithSize(v.Type(), len(node.Entries))
+	for _, entry := range node.Entries {
+		key := reflect.ValueOf(entry.Key).Convert(v.Type().Key())
+		if m.MapIndex(key).IsValid() {
+			return errorf(entry.KeyPos, "duplicate key %q", entry.Key)
+		}
+		value := reflect.New(v.Type().Elem()).Elem()
+		if err := decode(entry.Value, value); err != nil {
+			return err
+		}
+		m.SetMapIndex(key, value)
+	}
+	v.Set(m)
+	return nil
+}
+
+func decodeSlice(node *Node, v reflect.Value) error {
+	items := node.Items
+	switch {
+	case node.Kind == List:
+	case node.block:
+		// a single HCL block
+		items = []*Node{node}
+	default:
+		return mismatch(node, "list")
+	}
+	slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+	for i, item := range items {
+		if err := decode(item, slice.Index(i)); err != nil {
+			return err
+		}
+	}
+	v.Set(slice)
+	return nil
+}
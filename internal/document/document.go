@@ -0,0 +1,162 @@
+// Package document parses JSON, YAML and HCL documents into a tree of nodes that remember where they
+// appear in the source, and strictly decodes the tree into structs with json tags. Errors point at the
+// file, line and column of the offending value.
+package document
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Kind &
+type Kind int
+
+// Kinds of nodes
+const (
+	Null Kind = iota
+	Scalar
+	Map
+	List
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Scalar:
+		return "value"
+	case Map:
+		return "object"
+	case List:
+		return "list"
+	default:
+		return "null"
+	}
+}
+
+// Pos is a position in a document. Column is 0 when the parser does not report it.
+type Pos struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (p Pos) String() string {
+	switch {
+	case p.Line == 0:
+		return p.File
+	case p.Column == 0:
+		return fmt.Sprintf("%s:%d", p.File, p.Line)
+	default:
+		return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+	}
+}
+
+// Error is an error at a position in a document
+type Error struct {
+	Pos Pos
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+func errorf(pos Pos, format string, args ...interface{}) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Entry is a key of a map node along with its value
+type Entry struct {
+	Key    string
+	KeyPos Pos
+	Value  *Node
+}
+
+// Node is a value in a document. Scalars hold a string, float64 or bool in Value.
+type Node struct {
+	Kind    Kind
+	Pos     Pos
+	Value   interface{}
+	Entries []Entry
+	Items   []*Node
+
+	// block is set on HCL blocks, which can stand in for a list of one block or be repeated to
+	// form a list
+	block bool
+}
+
+// Get returns the value of key in a map node, or nil if the node has no such key
+func (n *Node) Get(key string) *Node {
+	if n == nil || n.Kind != Map {
+		return nil
+	}
+	for _, entry := range n.Entries {
+		if entry.Key == key {
+			return entry.Value
+		}
+	}
+	return nil
+}
+
+// Interface returns the node as plain go values: map[string]interface{}, []interface{}, string,
+// float64, bool or nil
+func (n *Node) Interface() interface{} {
+	switch n.Kind {
+	case Map:
+		m := make(map[string]interface{}, len(n.Entries))
+		for _, entry := range n.Entries {
+			m[entry.Key] = entry.Value.Interface()
+		}
+		return m
+	case List:
+		l := make([]interface{}, len(n.Items))
+		for i, item := range n.Items {
+			l[i] = item.Interface()
+		}
+		return l
+	default:
+		return n.Value
+	}
+}
+
+// Formats maps the file extensions of supported documents to their format
+var Formats = map[string]string{
+	".json": "json",
+	".yaml": "yaml",
+	".yml":  "yaml",
+	".hcl":  "hcl",
+}
+
+// Extensions returns the supported file extensions
+func Extensions() []string {
+	extensions := make([]string, 0, len(Formats))
+	for extension := range Formats {
+		extensions = append(extensions, extension)
+	}
+	sort.Strings(extensions)
+	return extensions
+}
+
+// ParseFile parses the document at path. The format is detected from the file extension and files
+// with any other extension are parsed as JSON.
+func ParseFile(path string) (*Node, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read file. Err: %v", err)
+	}
+	return Parse(path, data)
+}
+
+// Parse parses data in the format of the extension of file
+func Parse(file string, data []byte) (*Node, error) {
+	switch Formats[strings.ToLower(filepath.Ext(file))] {
+	case "yaml":
+		return parseYAML(file, data)
+	case "hcl":
+		return parseHCL(file, data)
+	default:
+		return parseJSON(file, data)
+	}
+}
@@ -0,0 +1,160 @@
+package document
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSuite struct {
+	suite.Suite
+}
+
+func TestRunSuite(t *testing.T) {
+	suite.Run(t, new(TestSuite))
+}
+
+type testItem struct {
+	Name  string                 `json:"name"`
+	Count int                    `json:"count"`
+	Tags  map[string]interface{} `json:"tags"`
+}
+
+type testDocument struct {
+	Enabled bool       `json:"enabled"`
+	Types   []string   `json:"types"`
+	Items   []testItem `json:"items"`
+	Default *testItem  `json:"default"`
+}
+
+var expectedDocument = testDocument{
+	Enabled: true,
+	Types:   []string{"a", "b"},
+	Items: []testItem{
+		{Name: "one", Count: 1, Tags: map[string]interface{}{"env": "prod", "size": float64(2)}},
+		{Name: "two"},
+	},
+	Default: &testItem{Name: "default"},
+}
+
+func (s *TestSuite) TestDecode() {
+	cases := []struct {
+		file string
+		data string
+	}{
+		{
+			file: "doc.json",
+			data: `{
+  "enabled": true,
+  "types": ["a", "b"],
+  "items": [
+    {"name": "one", "count": 1, "tags": {"env": "prod", "size": 2}},
+    {"name": "two"}
+  ],
+  "default": {"name": "default"}
+}`,
+		},
+		{
+			file: "doc.yml",
+			data: `enabled: true
+types: [a, b]
+items:
+  - name: one
+    count: 1
+    tags:
+      env: prod
+      size: 2
+  - name: two
+default:
+  name: default
+`,
+		},
+		{
+			file: "doc.hcl",
+			data: `enabled = true
+types = ["a", "b"]
+
+items {
+  name  = "one"
+  count = 1
+  tags = {
+    env  = "prod"
+    size = 2
+  }
+}
+
+items {
+  name = "two"
+}
+
+default {
+  name = "default"
+}
+`,
+		},
+	}
+
+	for _, c := range cases {
+		node, err := Parse(c.file, []byte(c.data))
+		s.Require().NoError(err, c.file)
+
+		var doc testDocument
+		s.NoError(Decode(node, &doc), c.file)
+		s.Equal(expectedDocument, doc, c.file)
+	}
+}
+
+func (s *TestSuite) TestDecodeSingleBlock() {
+	node, err := Parse("doc.hcl", []byte("items {\n  name = \"one\"\n}\n"))
+	s.Require().NoError(err)
+
+	var doc testDocument
+	s.NoError(Decode(node, &doc))
+	s.Equal([]testItem{{Name: "one"}}, doc.Items)
+}
+
+func (s *TestSuite) TestPositions() {
+	node, err := Parse("doc.json", []byte("{\n  \"items\": [\n    {\"name\": \"one\"}\n  ]\n}"))
+	s.Require().NoError(err)
+	s.Equal(Pos{File: "doc.json", Line: 1, Column: 1}, node.Pos)
+	s.Equal(Pos{File: "doc.json", Line: 2, Column: 3}, node.Entries[0].KeyPos)
+	s.Equal(Pos{File: "doc.json", Line: 2, Column: 12}, node.Get("items").Pos)
+	s.Equal(Pos{File: "doc.json", Line: 3, Column: 14}, node.Get("items").Items[0].Get("name").Pos)
+}
+
+func (s *TestSuite) TestErrors() {
+	cases := []struct {
+		file       string
+		data       string
+		errMessage string
+	}{
+		{"doc.json", "{\n  \"enabled\": true,\n  \"typse\": []\n}", `doc.json:3:3: unknown key "typse", did you mean "types"?`},
+		{"doc.json", "{\n  \"enabled\": true,\n}", "doc.json:3:1: invalid character"},
+		{"doc.json", `{"enabled": true} {}`, "doc.json:1:19: unexpected content after the end of the document"},
+		{"doc.json", `{"enabled": true, "enabled": false}`, `doc.json:1:19: duplicate key "enabled"`},
+		{"doc.json", `{"enabled": "yes"}`, "doc.json:1:13: expected a boolean but found a string"},
+		{"doc.json", `{"items": [{"count": 1.5}]}`, "doc.json:1:22: expected an integer but found a number"},
+		{"doc.json", `{"types": "a"}`, "doc.json:1:11: expected a list but found a string"},
+		{"doc.yaml", "items:\n  - name: one\n    other: 1\n", `doc.yaml:3:5: unknown key "other". Expected one of: count, name, tags`},
+		{"doc.yaml", "items:\n  - name: one\n name: two\n", "doc.yaml:2: did not find expected key"},
+		{"doc.hcl", "default {\n  name = 1\n}\n", "doc.hcl:2:10: expected a string but found a number"},
+		{"doc.hcl", "default \"label\" {\n  name = \"one\"\n}\n", "doc.hcl:1:9: block labels are not supported"},
+		{"doc.hcl", "default {\n  name = \"one\"\n}\ndefault {\n  name = \"two\"\n}\n", "doc.hcl:1:9: expected an object but found a list"},
+	}
+
+	for _, c := range cases {
+		node, err := Parse(c.file, []byte(c.data))
+		if err == nil {
+			var doc testDocument
+			err = Decode(node, &doc)
+		}
+		s.Error(err, c.data)
+		if err != nil {
+			s.Contains(err.Error(), c.errMessage, c.data)
+		}
+	}
+}
+
+func (s *TestSuite) TestExtensions() {
+	s.Equal([]string{".hcl", ".json", ".yaml", ".yml"}, Extensions())
+}
@@ -0,0 +1,81 @@
+package document
+
+import (
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/parser"
+	"github.com/hashicorp/hcl/hcl/token"
+)
+
+func parseHCL(file string, data []byte) (*Node, error) {
+	f, err := parser.Parse(data)
+	if err != nil {
+		if posErr, ok := err.(*parser.PosError); ok {
+			return nil, errorf(hclPos(file, posErr.Pos), "%v", posErr.Err)
+		}
+		return nil, errorf(Pos{File: file}, "%v", err)
+	}
+	list, ok := f.Node.(*ast.ObjectList)
+	if !ok {
+		return nil, errorf(hclPos(file, f.Node.Pos()), "expected attributes and blocks")
+	}
+	return convertHCLObject(file, list, Pos{File: file, Line: 1, Column: 1})
+}
+
+func hclPos(file string, pos token.Pos) Pos {
+	return Pos{File: file, Line: pos.Line, Column: pos.Column}
+}
+
+// convertHCLObject converts attributes and blocks to a map node. Blocks that are repeated with the
+// same key are collected into a list.
+func convertHCLObject(file string, list *ast.ObjectList, pos Pos) (*Node, error) {
+	node := &Node{Kind: Map, Pos: pos}
+	for _, item := range list.Items {
+		if len(item.Keys) > 1 {
+			return nil, errorf(hclPos(file, item.Keys[1].Pos()), "block labels are not supported")
+		}
+		key := item.Keys[0].Token.Value().(string)
+		keyPos := hclPos(file, item.Keys[0].Pos())
+		value, err := convertHCL(file, item.Val)
+		if err != nil {
+			return nil, err
+		}
+		value.block = !item.Assign.IsValid()
+
+		if existing := node.Get(key); existing != nil && value.block && existing.block {
+			if existing.Kind != List {
+				first := *existing
+				*existing = Node{Kind: List, Pos: first.Pos, Items: []*Node{&first}, block: true}
+			}
+			existing.Items = append(existing.Items, value)
+			continue
+		}
+		node.Entries = append(node.Entries, Entry{Key: key, KeyPos: keyPos, Value: value})
+	}
+	return node, nil
+}
+
+func convertHCL(file string, n ast.Node) (*Node, error) {
+	pos := hclPos(file, n.Pos())
+	switch v := n.(type) {
+	case *ast.ObjectType:
+		return convertHCLObject(file, v.List, pos)
+	case *ast.ListType:
+		node := &Node{Kind: List, Pos: pos}
+		for _, item := range v.List {
+			converted, err := convertHCL(file, item)
+			if err != nil {
+				return nil, err
+			}
+			node.Items = append(node.Items, converted)
+		}
+		return node, nil
+	case *ast.LiteralType:
+		value := v.Token.Value()
+		if i, ok := value.(int64); ok {
+			value = float64(i)
+		}
+		return &Node{Kind: Scalar, Pos: pos, Value: value}, nil
+	default:
+		return nil, errorf(pos, "unsupported value")
+	}
+}
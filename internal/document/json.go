@@ -0,0 +1,101 @@
+package document
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+type jsonParser struct {
+	file string
+	data []byte
+	dec  *json.Decoder
+}
+
+func parseJSON(file string, data []byte) (*Node, error) {
+	p := &jsonParser{file: file, data: data, dec: json.NewDecoder(bytes.NewReader(data))}
+	node, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if offset := p.dec.InputOffset(); len(bytes.TrimSpace(data[offset:])) > 0 {
+		return nil, errorf(p.pos(offset), "unexpected content after the end of the document")
+	}
+	return node, nil
+}
+
+// pos returns the position of the next token at or after offset
+func (p *jsonParser) pos(offset int64) Pos {
+	for int(offset) < len(p.data) && bytes.IndexByte([]byte(" \t\r\n,:"), p.data[offset]) >= 0 {
+		offset++
+	}
+	pos := Pos{File: p.file, Line: 1, Column: 1}
+	for _, c := range p.data[:offset] {
+		if c == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
+	}
+	return pos
+}
+
+func (p *jsonParser) token() (json.Token, Pos, error) {
+	pos := p.pos(p.dec.InputOffset())
+	tok, err := p.dec.Token()
+	if err != nil {
+		if syntaxErr, ok := err.(*json.SyntaxError); ok {
+			return nil, pos, errorf(p.pos(syntaxErr.Offset), "%v", syntaxErr)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, pos, errorf(p.pos(int64(len(p.data))), "unexpected end of JSON input")
+		}
+		return nil, pos, errorf(pos, "%v", err)
+	}
+	return tok, pos, nil
+}
+
+func (p *jsonParser) parseValue() (*Node, error) {
+	tok, pos, err := p.token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		node := &Node{Kind: Map, Pos: pos}
+		for p.dec.More() {
+			key, keyPos, err := p.token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			node.Entries = append(node.Entries, Entry{Key: key.(string), KeyPos: keyPos, Value: value})
+		}
+		if _, _, err := p.token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case json.Delim('['):
+		node := &Node{Kind: List, Pos: pos}
+		for p.dec.More() {
+			item, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			node.Items = append(node.Items, item)
+		}
+		if _, _, err := p.token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case nil:
+		return &Node{Kind: Null, Pos: pos}, nil
+	default:
+		return &Node{Kind: Scalar, Pos: pos, Value: tok}, nil
+	}
+}
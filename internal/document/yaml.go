@@ -0,0 +1,78 @@
+package document
+
+import (
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlErrorLine matches yaml.v3 syntax errors, which only have the line of the problem
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+func parseYAML(file string, data []byte) (*Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+			line, _ := strconv.Atoi(match[1])
+			return nil, errorf(Pos{File: file, Line: line}, "%s", match[2])
+		}
+		return nil, errorf(Pos{File: file}, "%v", err)
+	}
+	if len(doc.Content) == 0 {
+		return &Node{Kind: Null, Pos: Pos{File: file, Line: 1, Column: 1}}, nil
+	}
+	return convertYAML(file, doc.Content[0])
+}
+
+func convertYAML(file string, n *yaml.Node) (*Node, error) {
+	pos := Pos{File: file, Line: n.Line, Column: n.Column}
+	switch n.Kind {
+	case yaml.AliasNode:
+		return convertYAML(file, n.Alias)
+	case yaml.MappingNode:
+		node := &Node{Kind: Map, Pos: pos}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if key.Kind != yaml.ScalarNode {
+				return nil, errorf(Pos{File: file, Line: key.Line, Column: key.Column}, "keys must be strings")
+			}
+			converted, err := convertYAML(file, value)
+			if err != nil {
+				return nil, err
+			}
+			node.Entries = append(node.Entries, Entry{
+				Key:    key.Value,
+				KeyPos: Pos{File: file, Line: key.Line, Column: key.Column},
+				Value:  converted,
+			})
+		}
+		return node, nil
+	case yaml.SequenceNode:
+		node := &Node{Kind: List, Pos: pos}
+		for _, item := range n.Content {
+			converted, err := convertYAML(file, item)
+			if err != nil {
+				return nil, err
+			}
+			node.Items = append(node.Items, converted)
+		}
+		return node, nil
+	default:
+		var value interface{}
+		if err := n.Decode(&value); err != nil {
+			return nil, errorf(pos, "%v", err)
+		}
+		switch v := value.(type) {
+		case nil:
+			return &Node{Kind: Null, Pos: pos}, nil
+		case int:
+			value = float64(v)
+		case int64:
+			value = float64(v)
+		case uint64:
+			value = float64(v)
+		}
+		return &Node{Kind: Scalar, Pos: pos, Value: value}, nil
+	}
+}
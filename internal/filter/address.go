@@ -0,0 +1,219 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mupuri/go-tfdr/internal/models"
+)
+
+// selector is what a filter matches resources on. It is read from the address of a filter, or from
+// its filter properties.
+type selector struct {
+	properties models.FilterProperties
+	// indexKey limits the filter to one instance of the resource when hasIndexKey is set
+	indexKey    interface{}
+	hasIndexKey bool
+	// where limits the filter to the instances matching every predicate
+	where []*predicate
+}
+
+var (
+	selectors   = make(map[string]*selector)
+	selectorsMu sync.Mutex
+)
+
+// filterSelector returns the selector of filter. Invalid addresses and predicates never match; they
+// are reported when the filter config is read.
+func filterSelector(filter models.Filter) *selector {
+	sel := &selector{properties: filter.FilterProperties}
+	if filter.Address != "" {
+		parsed, err := parseAddress(filter.Address)
+		if err != nil {
+			return &selector{properties: models.FilterProperties{Mode: "-"}}
+		}
+		copied := *parsed
+		sel = &copied
+	}
+	for _, p := range filter.FilterProperties.Where {
+		compiled, err := compilePredicate(p)
+		if err != nil {
+			return &selector{properties: models.FilterProperties{Mode: "-"}}
+		}
+		sel.where = append(sel.where, compiled)
+	}
+	return sel
+}
+
+// parseAddress parses a resource or resource instance address, the way terraform state list prints
+// them, into a selector, e.g. module.a.module.b.aws_s3_bucket.logs["primary"], module.app[2].aws_instance.web
+// or data.aws_ami.base. Module names, types and names can contain the wildcards * and ?.
+func parseAddress(address string) (*selector, error) {
+	selectorsMu.Lock()
+	defer selectorsMu.Unlock()
+	if sel, ok := selectors[address]; ok {
+		return sel, nil
+	}
+
+	steps, err := splitAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid address %q. Err: %v", address, err)
+	}
+
+	sel := &selector{properties: models.FilterProperties{Mode: "managed"}}
+	modules := make([]string, 0)
+	i := 0
+	for ; i+1 < len(steps) && steps[i].name == "module"; i += 2 {
+		if steps[i].key != "" {
+			return nil, fmt.Errorf("Invalid address %q. Err: unexpected index after module", address)
+		}
+		module := "module." + steps[i+1].name
+		if key := steps[i+1].key; key != "" {
+			indexKey, _ := parseIndexKey(key)
+			module += formatIndexKey(indexKey)
+		}
+		modules = append(modules, module)
+	}
+	if i < len(steps) && steps[i].name == "data" && steps[i].key == "" {
+		sel.properties.Mode = "data"
+		i++
+	}
+	if len(steps)-i != 2 {
+		return nil, fmt.Errorf("Invalid address %q. Err: expected [module.<name>.]...[data.]<type>.<name>[<key>]", address)
+	}
+	if steps[i].key != "" {
+		return nil, fmt.Errorf("Invalid address %q. Err: unexpected index after resource type", address)
+	}
+
+	sel.properties.Module = strings.Join(modules, ".")
+	sel.properties.Type = steps[i].name
+	sel.properties.Name = steps[i+1].name
+	if key := steps[i+1].key; key != "" {
+		sel.indexKey, _ = parseIndexKey(key)
+		sel.hasIndexKey = true
+	}
+	selectors[address] = sel
+	return sel, nil
+}
+
+// addressStep is a name of an address along with its index, e.g. app and ["east"] in module.app["east"]
+type addressStep struct {
+	name string
+	// key is the index as written in the address, including the brackets
+	key string
+}
+
+func splitAddress(address string) ([]addressStep, error) {
+	steps := make([]addressStep, 0)
+	for i := 0; i < len(address); {
+		end := strings.IndexAny(address[i:], ".[")
+		if end < 0 {
+			end = len(address) - i
+		}
+		if end == 0 {
+			return nil, fmt.Errorf("missing name at position %d", i)
+		}
+		step := addressStep{name: address[i : i+end]}
+		i += end
+
+		if i < len(address) && address[i] == '[' {
+			end, err := indexEnd(address, i)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := parseIndexKey(address[i:end]); err != nil {
+				return nil, err
+			}
+			step.key = address[i:end]
+			i = end
+		}
+		steps = append(steps, step)
+
+		if i < len(address) {
+			if address[i] != '.' || i == len(address)-1 {
+				return nil, fmt.Errorf("unexpected %q at position %d", address[i], i)
+			}
+			i++
+		}
+	}
+	return steps, nil
+}
+
+// indexEnd returns the position after the ] closing the index that starts at position start of address
+func indexEnd(address string, start int) (int, error) {
+	quoted := false
+	for i := start + 1; i < len(address); i++ {
+		switch {
+		case quoted && address[i] == '\\':
+			i++
+		case address[i] == '"':
+			quoted = !quoted
+		case !quoted && address[i] == ']':
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("missing ] after position %d", start)
+}
+
+// parseIndexKey parses an index written in brackets, either a count index or a quoted for_each key.
+// Count indexes are returned as float64, the type json uses when decoding state.
+func parseIndexKey(key string) (interface{}, error) {
+	inner := key[1 : len(key)-1]
+	if strings.HasPrefix(inner, `"`) {
+		k, err := strconv.Unquote(inner)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quoted key %s", inner)
+		}
+		return k, nil
+	}
+	index, err := strconv.Atoi(inner)
+	if err != nil || index < 0 {
+		return nil, fmt.Errorf("invalid index %s", key)
+	}
+	return float64(index), nil
+}
+
+// formatIndexKey formats an index key the way terraform writes it in addresses
+func formatIndexKey(indexKey interface{}) string {
+	if k, ok := indexKey.(string); ok {
+		return fmt.Sprintf("[%q]", k)
+	}
+	return fmt.Sprintf("[%v]", indexKey)
+}
+
+// match returns true if the selector matches resource. A selector with an index key only matches
+// resources that have an instance with that key.
+func (s *selector) match(resource *models.Resource) bool {
+	if !matchFilterProperties(resource, s.properties) {
+		return false
+	}
+	if !s.selectsInstances() {
+		return true
+	}
+	for _, instance := range resource.Instances {
+		if s.selects(instance) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectsInstances returns true if the selector only selects some instances of the resources it matches
+func (s *selector) selectsInstances() bool {
+	return s.hasIndexKey || len(s.where) > 0
+}
+
+// selects returns true if instance is one of the instances of a matched resource selected by the selector
+func (s *selector) selects(instance models.Instance) bool {
+	if s.hasIndexKey && !containsIndexKey([]interface{}{s.indexKey}, instance.IndexKey) {
+		return false
+	}
+	for _, p := range s.where {
+		if !p.matches(instance) {
+			return false
+		}
+	}
+	return true
+}
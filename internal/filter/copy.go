@@ -0,0 +1,40 @@
+package filter
+
+import "github.com/mupuri/go-tfdr/internal/models"
+
+// copyResource returns a deep copy of resource so filter funcs can modify it freely
+func copyResource(resource models.Resource) models.Resource {
+	c := resource
+	if resource.Instances != nil {
+		c.Instances = make([]models.Instance, len(resource.Instances))
+		for i, instance := range resource.Instances {
+			c.Instances[i] = instance
+			if instance.Attributes != nil {
+				c.Instances[i].Attributes = copyValue(instance.Attributes).(map[string]interface{})
+			}
+			if instance.Dependencies != nil {
+				c.Instances[i].Dependencies = append([]string{}, instance.Dependencies...)
+			}
+		}
+	}
+	return c
+}
+
+func copyValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			m[k] = copyValue(e)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(t))
+		for i, e := range t {
+			s[i] = copyValue(e)
+		}
+		return s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,36 @@
+package filter
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// rewriteDependencies points instance dependencies on renamed resources at their new address and
+// removes dependencies on resources that are not in the filtered state
+func rewriteDependencies(result *Result) {
+	addresses := make(map[string]bool, len(result.Resources))
+	for _, resource := range result.Resources {
+		addresses[resource.Address()] = true
+	}
+
+	for r := range result.Resources {
+		resource := &result.Resources[r]
+		for i := range resource.Instances {
+			instance := &resource.Instances[i]
+			if instance.Dependencies == nil {
+				continue
+			}
+			dependencies := make([]string, 0, len(instance.Dependencies))
+			for _, dependency := range instance.Dependencies {
+				if newAddress, ok := result.Renames[dependency]; ok {
+					logrus.Debugf("%s: renamed dependency %s to %s", resource.InstanceAddress(*instance), dependency, newAddress)
+					dependency = newAddress
+				} else if !addresses[dependency] {
+					logrus.Debugf("%s: removed dependency %s which is not in the new state", resource.InstanceAddress(*instance), dependency)
+					continue
+				}
+				dependencies = append(dependencies, dependency)
+			}
+			instance.Dependencies = dependencies
+		}
+	}
+}
@@ -1,82 +1,380 @@
 package filter
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/mupuri/go-tfdr/internal/document"
 	"github.com/mupuri/go-tfdr/internal/models"
 	"github.com/mupuri/go-tfdr/internal/tfdrerrors"
 )
 
+// Result holds the resources kept by a filter func along with the resources it renamed
+type Result struct {
+	Resources []models.Resource
+	// Renames maps the original address of each renamed resource to its new address
+	Renames map[string]string
+}
+
+// ResourceFilterFunc returns the resource to keep in the filtered state, or nil to leave it out
+type ResourceFilterFunc func(resource *models.Resource, filterConfig *models.FilterConfig) (*models.Resource, error)
+
 // StateFilter &
-func StateFilter(vs []models.Resource, f func(*models.Resource, *models.FilterConfig) *models.Resource, configFileName string) ([]models.Resource, error) {
-	filterConfig, err := readFiltersFromFile(configFileName)
+func StateFilter(vs []models.Resource, f ResourceFilterFunc, configFileName string) ([]models.Resource, error) {
+	filterConfig, err := ReadFilterConfig(configFileName)
+	if err != nil {
+		return nil, err
+	}
+	result, err := FilterStateResources(vs, f, filterConfig)
+	if err != nil {
+		return nil, err
+	}
+	return result.Resources, nil
+}
+
+// ReadFilterConfig reads and validates a filter config file
+func ReadFilterConfig(configFileName string) (*models.FilterConfig, error) {
+	return ReadFilterConfigWithVars(configFileName, nil)
+}
+
+// ReadFilterConfigWithVars reads and validates a filter config file along with the files it includes,
+// replacing ${var.<name>} with the values of vars
+func ReadFilterConfigWithVars(configFileName string, vars map[string]string) (*models.FilterConfig, error) {
+	filterConfig, err := readFiltersFromFileWithVars(configFileName, vars)
 	if err != nil {
 		return nil, tfdrerrors.ErrReadFilterFile{Err: err}
 	}
-	vsf := make([]models.Resource, 0)
+	return filterConfig, nil
+}
+
+// FilterStateResources runs f against a copy of every resource in vs. The resources in vs are
+// left untouched so they can be compared against the result. Dependencies of the filtered resources
+// are updated to follow renames and to drop resources that were filtered out.
+func FilterStateResources(vs []models.Resource, f ResourceFilterFunc, filterConfig *models.FilterConfig) (*Result, error) {
+	result := &Result{
+		Resources: make([]models.Resource, 0),
+		Renames:   make(map[string]string),
+	}
 	for _, v := range vs {
-		result := f(&v, filterConfig)
-		if result != nil {
-			vsf = append(vsf, *result)
+		resource := copyResource(v)
+		filtered, err := f(&resource, filterConfig)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to filter resource %s. Err: %v", v.Address(), err)
+		}
+		if filtered == nil {
+			continue
 		}
+		if filtered.Address() != v.Address() {
+			result.Renames[v.Address()] = filtered.Address()
+		}
+		result.Resources = append(result.Resources, *filtered)
 	}
-	return vsf, nil
+	rewriteDependencies(result)
+	return result, nil
 }
 
 // CopyResourceFilterFunc &
-var CopyResourceFilterFunc = func(resource *models.Resource, filterConfig *models.FilterConfig) *models.Resource {
-	for _, globalResource := range filterConfig.GlobalResourceTypes {
-		if resource.Type == globalResource {
-			return resource
+var CopyResourceFilterFunc ResourceFilterFunc = func(resource *models.Resource, filterConfig *models.FilterConfig) (*models.Resource, error) {
+	_, excludes := matchFilters(resource, filterConfig.Exclude)
+	if selectsAll(excludes) {
+		return nil, nil
+	}
+	if len(excludes) > 0 {
+		resource.Instances = unselectedInstances(resource, excludes)
+		if len(resource.Instances) == 0 {
+			return nil, nil
 		}
 	}
-	for _, filter := range filterConfig.Filters {
-		if resource.Mode == "managed" && resource.Module == filter.FilterProperties.Module && resource.Name == filter.FilterProperties.Name && resource.Type == filter.FilterProperties.Type {
-			if filter.NewProperties.Name != "" {
-				resource.Name = filter.NewProperties.Name
-			}
 
-			for k, v := range filter.NewProperties.Attributes {
-				resource.Instances[0].Attributes[k] = v
+	if matchGlobalResourceTypes(resource, filterConfig.GlobalResourceTypes) {
+		if err := rewriteProvider(resource, filterConfig.Providers, ""); err != nil {
+			return nil, err
+		}
+		return resource, nil
+	}
+	excludeMode := filterConfig.Mode == models.FilterModeExclude
+	filters, selectors := matchFilters(resource, filterConfig.Filters)
+	if len(filters) == 0 {
+		if !excludeMode {
+			return nil, nil
+		}
+		if err := rewriteProvider(resource, filterConfig.Providers, ""); err != nil {
+			return nil, err
+		}
+		return resource, nil
+	}
+
+	name, provider, err := copyTarget(resource, filters, selectors)
+	if err != nil {
+		return nil, err
+	}
+	if err := rewriteProvider(resource, filterConfig.Providers, provider); err != nil {
+		return nil, err
+	}
+	resource.Name = name
+
+	// each instance is copied with the attribute overrides of the first filter that selects it. In
+	// exclude mode instances no filter selects are copied unchanged.
+	if resource.Instances != nil {
+		instances := make([]models.Instance, 0, len(resource.Instances))
+		for _, instance := range resource.Instances {
+			selected := false
+			for i, sel := range selectors {
+				if sel.selects(instance) {
+					if err := setAttributes(resource, &instance, filters[i].NewProperties); err != nil {
+						return nil, err
+					}
+					selected = true
+					break
+				}
+			}
+			if selected || excludeMode {
+				instances = append(instances, instance)
 			}
+		}
+		resource.Instances = instances
+	}
+
+	return resource, nil
+}
 
-			return resource
+// copyTarget returns the name and provider resource is copied with. Each instance is copied with the
+// first filter that selects it, but all instances are copied to one resource, so those filters have to
+// agree on both. The first filter is used when none of them selects an instance.
+func copyTarget(resource *models.Resource, filters []models.Filter, selectors []*selector) (string, string, error) {
+	used := make([]bool, len(filters))
+	selected := false
+	for _, instance := range resource.Instances {
+		for i, sel := range selectors {
+			if sel.selects(instance) {
+				used[i], selected = true, true
+				break
+			}
 		}
 	}
+	if !selected {
+		used[0] = true
+	}
 
-	return nil
+	name, provider := "", ""
+	var first models.Filter
+	for i, filter := range filters {
+		if !used[i] {
+			continue
+		}
+		filterName := resource.Name
+		if filter.NewProperties.Name != "" {
+			filterName = expandPattern(selectors[i].properties.Name, resource.Name, filter.NewProperties.Name)
+			if filterName == "" {
+				return "", "", fmt.Errorf("New name %q expands to an empty name", filter.NewProperties.Name)
+			}
+		}
+		switch {
+		case name == "":
+			first, name, provider = filter, filterName, filter.NewProperties.Provider
+		case filterName != name:
+			return "", "", fmt.Errorf("%s and %s copy instances of %s with different names %s and %s",
+				describeFilter(first), describeFilter(filter), resource.Address(), name, filterName)
+		case filter.NewProperties.Provider != provider:
+			return "", "", fmt.Errorf("%s and %s copy instances of %s with different providers %q and %q",
+				describeFilter(first), describeFilter(filter), resource.Address(), provider, filter.NewProperties.Provider)
+		}
+	}
+	return name, provider, nil
 }
 
 // DeleteResourceFilterFunc &
-var DeleteResourceFilterFunc = func(resource *models.Resource, filterConfig *models.FilterConfig) *models.Resource {
-	for _, globalResource := range filterConfig.GlobalResourceTypes {
-		if resource.Type == globalResource {
-			return nil
+var DeleteResourceFilterFunc ResourceFilterFunc = func(resource *models.Resource, filterConfig *models.FilterConfig) (*models.Resource, error) {
+	_, excludes := matchFilters(resource, filterConfig.Exclude)
+	if selectsAll(excludes) {
+		return resource, nil
+	}
+
+	deleteAll := filterConfig.Mode == models.FilterModeExclude || matchGlobalResourceTypes(resource, filterConfig.GlobalResourceTypes)
+	_, selectors := matchFilters(resource, filterConfig.Filters)
+	if !deleteAll && len(selectors) == 0 {
+		return resource, nil
+	}
+
+	// only excluded instances and instances that no filter selects are kept
+	instances := make([]models.Instance, 0, len(resource.Instances))
+	for _, instance := range resource.Instances {
+		if selectedBy(excludes, instance) || !(deleteAll || selectedBy(selectors, instance)) {
+			instances = append(instances, instance)
+		}
+	}
+	if len(instances) == 0 {
+		return nil, nil
+	}
+	resource.Instances = instances
+	return resource, nil
+}
+
+func matchGlobalResourceTypes(resource *models.Resource, globalResourceTypes []string) bool {
+	for _, globalResource := range globalResourceTypes {
+		if matchPattern(globalResource, resource.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFilters returns the filters that match resource along with their selectors
+func matchFilters(resource *models.Resource, filters []models.Filter) ([]models.Filter, []*selector) {
+	matched := make([]models.Filter, 0)
+	selectors := make([]*selector, 0)
+	for _, filter := range filters {
+		if sel := filterSelector(filter); sel.match(resource) {
+			matched = append(matched, filter)
+			selectors = append(selectors, sel)
 		}
 	}
+	return matched, selectors
+}
+
+// selectsAll returns true if any of selectors selects every instance of the resources it matches
+func selectsAll(selectors []*selector) bool {
+	for _, sel := range selectors {
+		if !sel.selectsInstances() {
+			return true
+		}
+	}
+	return false
+}
 
-	for _, filter := range filterConfig.Filters {
-		if resource.Mode == "managed" && resource.Module == filter.FilterProperties.Module && resource.Name == filter.FilterProperties.Name && resource.Type == filter.FilterProperties.Type {
-			return nil
+// selectedBy returns true if any of selectors selects instance
+func selectedBy(selectors []*selector, instance models.Instance) bool {
+	for _, sel := range selectors {
+		if sel.selects(instance) {
+			return true
 		}
 	}
+	return false
+}
 
-	return resource
+// unselectedInstances returns the instances of resource that none of selectors selects
+func unselectedInstances(resource *models.Resource, selectors []*selector) []models.Instance {
+	instances := make([]models.Instance, 0, len(resource.Instances))
+	for _, instance := range resource.Instances {
+		if !selectedBy(selectors, instance) {
+			instances = append(instances, instance)
+		}
+	}
+	return instances
 }
 
 func readFiltersFromFile(configFileName string) (*models.FilterConfig, error) {
-	filterConfigFile, err := os.Open(configFileName)
+	return readFiltersFromFileWithVars(configFileName, nil)
+}
+
+func readFiltersFromFileWithVars(configFileName string, vars map[string]string) (*models.FilterConfig, error) {
+	l := &loader{vars: vars, loaded: make(map[string]bool)}
+	filterConfig, err := l.load(configFileName)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to read file. Err: %v", err)
+		return nil, err
 	}
-	configByteValue, _ := ioutil.ReadAll(filterConfigFile)
 
-	var filterConfig models.FilterConfig
+	if err := validateFilterConfig(filterConfig); err != nil {
+		return nil, err
+	}
 
-	json.Unmarshal(configByteValue, &filterConfig)
+	return filterConfig, nil
+}
+
+// loader reads filter config files and the files they include
+type loader struct {
+	vars map[string]string
+	// loaded holds the absolute paths of the files read so far. Files included more than once are only read once.
+	loaded map[string]bool
+	// stack holds the absolute paths of the files being read, from the first file to the current one,
+	// and paths holds them as they were named
+	stack []string
+	paths []string
+}
 
+func (l *loader) load(path string) (*models.FilterConfig, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, loading := range l.stack {
+		if loading == abs {
+			cycle := append(append([]string{}, l.paths[i:]...), path)
+			return nil, fmt.Errorf("Include cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	if l.loaded[abs] {
+		return &models.FilterConfig{}, nil
+	}
+	l.loaded[abs] = true
+	l.stack = append(l.stack, abs)
+	l.paths = append(l.paths, path)
+	defer func() {
+		l.stack = l.stack[:len(l.stack)-1]
+		l.paths = l.paths[:len(l.paths)-1]
+	}()
+
+	node, err := document.ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := interpolate(node, l.vars); err != nil {
+		return nil, err
+	}
+	var filterConfig models.FilterConfig
+	if err := document.Decode(node, &filterConfig); err != nil {
+		return nil, err
+	}
+
+	for i, include := range filterConfig.Include {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		included, err := l.load(include)
+		if err != nil {
+			pos := node.Get("include").Items[i].Pos
+			return nil, &document.Error{Pos: pos, Msg: fmt.Sprintf("Unable to include %s. Err: %v", filterConfig.Include[i], err)}
+		}
+		mergeFilterConfig(&filterConfig, included)
+	}
 	return &filterConfig, nil
 }
+
+// mergeFilterConfig adds the settings of an included filter config to filterConfig. Filters of
+// filterConfig are matched before the included filters, and its mode, providers and output renames
+// and values take precedence.
+func mergeFilterConfig(filterConfig *models.FilterConfig, included *models.FilterConfig) {
+	if filterConfig.Mode == "" {
+		filterConfig.Mode = included.Mode
+	}
+	filterConfig.GlobalResourceTypes = append(filterConfig.GlobalResourceTypes, included.GlobalResourceTypes...)
+	filterConfig.Filters = append(filterConfig.Filters, included.Filters...)
+	filterConfig.Exclude = append(filterConfig.Exclude, included.Exclude...)
+	filterConfig.Replacements = append(filterConfig.Replacements, included.Replacements...)
+	filterConfig.Outputs.Include = append(filterConfig.Outputs.Include, included.Outputs.Include...)
+	filterConfig.Outputs.Exclude = append(filterConfig.Outputs.Exclude, included.Outputs.Exclude...)
+	filterConfig.Providers = mergeMap(filterConfig.Providers, included.Providers)
+	filterConfig.Outputs.Rename = mergeMap(filterConfig.Outputs.Rename, included.Outputs.Rename)
+	for name, value := range included.Outputs.Values {
+		if filterConfig.Outputs.Values == nil {
+			filterConfig.Outputs.Values = make(map[string]interface{})
+		}
+		if _, ok := filterConfig.Outputs.Values[name]; !ok {
+			filterConfig.Outputs.Values[name] = value
+		}
+	}
+}
+
+// mergeMap adds the keys of included that m does not have to m
+func mergeMap(m map[string]string, included map[string]string) map[string]string {
+	for k, v := range included {
+		if m == nil {
+			m = make(map[string]string)
+		}
+		if _, ok := m[k]; !ok {
+			m[k] = v
+		}
+	}
+	return m
+}
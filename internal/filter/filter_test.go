@@ -1,6 +1,9 @@
 package filter
 
 import (
+	"bytes"
+	"fmt"
+	"os"
 	"testing"
 
 	"github.com/mupuri/go-tfdr/internal/models"
@@ -29,6 +32,44 @@ func (s *TestSuite) TestReadFiltersFromFileError() {
 	s.Nil(filterConfig)
 }
 
+func (s *TestSuite) TestReadFiltersFromFileFormats() {
+	expected, err := readFiltersFromFile("./testdata/filterConfig.json")
+	s.Require().NoError(err)
+
+	for _, fileName := range []string{"./testdata/filterConfig.yaml", "./testdata/filterConfig.hcl"} {
+		filterConfig, err := readFiltersFromFile(fileName)
+		s.NoError(err, fileName)
+		s.Equal(expected, filterConfig, fileName)
+	}
+}
+
+func (s *TestSuite) TestReadFiltersFromFileInvalid() {
+	cases := []struct {
+		fileName   string
+		errMessage string
+	}{
+		{
+			fileName:   "./testdata/typoFilterConfig.yaml",
+			errMessage: `./testdata/typoFilterConfig.yaml:2:5: unknown key "filter_propertes", did you mean "filter_properties"?`,
+		},
+		{
+			fileName:   "./testdata/typoFilterConfig.json",
+			errMessage: `./testdata/typoFilterConfig.json:7:17: unknown key "nmae", did you mean "name"?`,
+		},
+		{
+			fileName:   "./testdata/invalidFilterConfig.hcl",
+			errMessage: "./testdata/invalidFilterConfig.hcl:4:19: literal not terminated",
+		},
+	}
+
+	for _, c := range cases {
+		filterConfig, err := ReadFilterConfig(c.fileName)
+		s.Error(err, c.fileName)
+		s.Contains(err.Error(), c.errMessage)
+		s.Nil(filterConfig)
+	}
+}
+
 func (s *TestSuite) TestReadStateFilterError() {
 	var res = testutils.NewStateResources()
 
@@ -81,3 +122,1068 @@ func contains(s []models.Resource, module string, mode string, typ string) bool
 	}
 	return false
 }
+
+func (s *TestSuite) TestFilterStateResources() {
+	var res = testutils.NewStateResources()
+
+	filterConfig, err := ReadFilterConfig("./testdata/filterConfig.json")
+	s.NoError(err)
+	result, err := FilterStateResources(res, CopyResourceFilterFunc, filterConfig)
+	s.NoError(err)
+	s.Equal(map[string]string{
+		"module.test_module_1.type_1.orig_name_1": "module.test_module_1.type_1.new_name_1",
+	}, result.Renames)
+	s.Equal("orig_name_1", get(res, "module.test_module_1", "managed", "type_1").Name)
+	s.Equal("old_value_1", get(res, "module.test_module_2", "managed", "type_2").Instances[0].Attributes["attr1"])
+}
+
+func (s *TestSuite) TestCopyStateFilterPatterns() {
+	var res = testutils.NewStateResources()
+
+	fr, err := StateFilter(res, CopyResourceFilterFunc, "./testdata/patternFilterConfig.json")
+	s.NoError(err)
+	s.Equal(7, len(fr))
+	s.Equal("dr_name_3", get(fr, "module.test_module_3", "managed", "type_3").Name)
+	s.Equal("dr_name_5", get(fr, "module.test_module_5", "managed", "type_5").Name)
+	s.Equal("glob_8", get(fr, "module.test_module_8", "managed", "type_8").Name)
+	s.False(contains(fr, "module.test_module_6", "managed", "type_6"))
+	s.True(contains(fr, "module.test_global_module_2", "managed", "aws_iam_access_key"))
+	s.False(contains(fr, "module.test_global_module_0", "managed", "aws_cloudfront_distribution"))
+}
+
+func (s *TestSuite) TestDeleteStateFilterPatterns() {
+	var res = testutils.NewStateResources()
+
+	fr, err := StateFilter(res, DeleteResourceFilterFunc, "./testdata/patternFilterConfig.json")
+	s.NoError(err)
+	s.Equal(len(res)-7, len(fr))
+	s.False(contains(fr, "module.test_module_4", "managed", "type_4"))
+	s.True(contains(fr, "module.test_module_6", "managed", "type_6"))
+}
+
+func (s *TestSuite) TestInvalidPattern() {
+	filterConfig, err := readFiltersFromFile("./testdata/invalidPatternFilterConfig.json")
+	s.Error(err)
+	s.Contains(err.Error(), `Invalid pattern "re:module\\.test_module_[3-5"`)
+	s.Nil(filterConfig)
+}
+
+func (s *TestSuite) TestMatchPattern() {
+	cases := []struct {
+		pattern string
+		value   string
+		matches bool
+	}{
+		{"module.app", "module.app", true},
+		{"module.app", "module_app", false},
+		{"module.app_*", "module.app_1", true},
+		{"module.app_*", "module.app", false},
+		{"module.app_*", "module.app_1.module.inner", false},
+		{"module.*", "module.a.module.b", false},
+		{"module.app_?", "module.app_1", true},
+		{"module.app_?", "module.app.b", false},
+		{"module.app[?]", "module.app[1]", true},
+		{"re:module\\.app_[0-9]+", "module.app_12", true},
+		{"re:module\\.app_[0-9]+", "module.app_12.module.b", false},
+		{"", "", true},
+		{"", "module.app", false},
+	}
+
+	for _, c := range cases {
+		s.Equal(c.matches, matchPattern(c.pattern, c.value), "%s should match %s: %v", c.pattern, c.value, c.matches)
+	}
+}
+
+func newInstances(indexKeys ...interface{}) []models.Instance {
+	instances := make([]models.Instance, 0, len(indexKeys))
+	for _, k := range indexKeys {
+		instances = append(instances, models.Instance{
+			IndexKey:   k,
+			Attributes: map[string]interface{}{"attr1": "old_value_1"},
+		})
+	}
+	return instances
+}
+
+func (s *TestSuite) TestCopyResourceFilterFuncInstances() {
+	cases := []struct {
+		instances []models.Instance
+		indexKeys []interface{}
+		expected  []string
+		message   string
+	}{
+		{nil, nil, []string{}, "resource with no instances should be copied"},
+		{newInstances(float64(0), float64(1), float64(2)), nil, []string{"new", "new", "new"}, "every count instance should be updated"},
+		{newInstances(float64(0), float64(1), float64(2)), []interface{}{1, float64(2)}, []string{"old_value_1", "new", "new"}, "selected count instances should be updated"},
+		{newInstances("a", "b"), []interface{}{"b"}, []string{"old_value_1", "new"}, "selected for_each instances should be updated"},
+		{newInstances(nil), []interface{}{"b"}, []string{"old_value_1"}, "unselected instances should not be updated"},
+	}
+
+	filterConfig := &models.FilterConfig{}
+	for _, c := range cases {
+		filterConfig.Filters = []models.Filter{
+			{
+				FilterProperties: models.FilterProperties{Module: "module.a", Type: "type_a", Name: "name_a"},
+				NewProperties: models.NewProperties{
+					Attributes: map[string]interface{}{"attr1": "new"},
+					IndexKeys:  c.indexKeys,
+				},
+			},
+		}
+		resource := &models.Resource{Module: "module.a", Mode: "managed", Type: "type_a", Name: "name_a", Instances: c.instances}
+
+		result, err := CopyResourceFilterFunc(resource, filterConfig)
+		s.NoError(err, c.message)
+		s.NotNil(result, c.message)
+		values := make([]string, 0)
+		for _, instance := range result.Instances {
+			values = append(values, instance.Attributes["attr1"].(string))
+		}
+		s.Equal(c.expected, values, c.message)
+	}
+}
+
+func (s *TestSuite) TestParseAddress() {
+	cases := []struct {
+		address  string
+		expected *selector
+		err      string
+	}{
+		{"aws_s3_bucket.logs", &selector{properties: models.FilterProperties{Mode: "managed", Type: "aws_s3_bucket", Name: "logs"}}, ""},
+		{"data.aws_ami.base", &selector{properties: models.FilterProperties{Mode: "data", Type: "aws_ami", Name: "base"}}, ""},
+		{
+			`module.a.module.b.aws_s3_bucket.logs["primary"]`,
+			&selector{properties: models.FilterProperties{Mode: "managed", Module: "module.a.module.b", Type: "aws_s3_bucket", Name: "logs"}, indexKey: "primary", hasIndexKey: true},
+			"",
+		},
+		{
+			"module.app[2].data.aws_ami.base[0]",
+			&selector{properties: models.FilterProperties{Mode: "data", Module: "module.app[2]", Type: "aws_ami", Name: "base"}, indexKey: float64(0), hasIndexKey: true},
+			"",
+		},
+		{
+			`module.app["us.east"].aws_instance.web_*`,
+			&selector{properties: models.FilterProperties{Mode: "managed", Module: `module.app["us.east"]`, Type: "aws_instance", Name: "web_*"}},
+			"",
+		},
+		{"", nil, `Invalid address "". Err: expected [module.<name>.]...[data.]<type>.<name>[<key>]`},
+		{"aws_s3_bucket", nil, `Invalid address "aws_s3_bucket". Err: expected [module.<name>.]...[data.]<type>.<name>[<key>]`},
+		{"module.a.aws_s3_bucket.logs.extra", nil, `Invalid address "module.a.aws_s3_bucket.logs.extra". Err: expected [module.<name>.]...[data.]<type>.<name>[<key>]`},
+		{"aws_s3_bucket..logs", nil, `Invalid address "aws_s3_bucket..logs". Err: missing name at position 14`},
+		{"aws_s3_bucket.logs[", nil, `Invalid address "aws_s3_bucket.logs[". Err: missing ] after position 18`},
+		{"aws_s3_bucket.logs[-1]", nil, `Invalid address "aws_s3_bucket.logs[-1]". Err: invalid index [-1]`},
+		{"aws_s3_bucket[0].logs", nil, `Invalid address "aws_s3_bucket[0].logs". Err: unexpected index after resource type`},
+		{"module[0].a.aws_s3_bucket.logs", nil, `Invalid address "module[0].a.aws_s3_bucket.logs". Err: unexpected index after module`},
+	}
+
+	for _, c := range cases {
+		sel, err := parseAddress(c.address)
+		if c.err != "" {
+			s.EqualError(err, c.err, c.address)
+			continue
+		}
+		s.NoError(err, c.address)
+		s.Equal(c.expected, sel, c.address)
+	}
+}
+
+func (s *TestSuite) TestMatchModule() {
+	cases := []struct {
+		pattern string
+		module  string
+		matches bool
+	}{
+		{"", "", true},
+		{"module.app", "module.app", true},
+		{"module.app", `module.app["east"]`, true},
+		{"module.app", "module.app[0]", true},
+		{`module.app["east"]`, `module.app["east"]`, true},
+		{`module.app["east"]`, `module.app["west"]`, false},
+		{`module.app["east"]`, "module.app", false},
+		{"module.app[1]", "module.app[1]", true},
+		{"module.app[1]", "module.app[10]", false},
+		{"module.app[1]", `module.app["1"]`, false},
+		{"module.app.module.db", `module.app["east"].module.db[0]`, true},
+		{`module.app["east"].module.db`, `module.app["west"].module.db`, false},
+		{"module.app", "module.app.module.db", false},
+		{"module.app_*", `module.app_1["east"]`, true},
+		{"module.app_*", "module.app_1.module.inner", false},
+		{"module.app_*.module.*", `module.app_1.module.inner["a"]`, true},
+		{"module.app[*]", `module.app["east"]`, true},
+		{`re:module\.app`, `module.app["east"]`, false},
+		{`re:module\.app\[.*\]`, `module.app["east"]`, true},
+	}
+
+	for _, c := range cases {
+		s.Equal(c.matches, matchModule(c.pattern, c.module), "%s should match %s: %v", c.pattern, c.module, c.matches)
+	}
+}
+
+func (s *TestSuite) TestDeleteResourceFilterFuncModuleGlob() {
+	resources := []models.Resource{
+		{Module: "module.app_1", Mode: "managed", Type: "aws_instance", Name: "web"},
+		{Module: "module.app_1.module.inner", Mode: "managed", Type: "aws_instance", Name: "web"},
+	}
+	filterConfig := &models.FilterConfig{Filters: []models.Filter{{
+		FilterProperties: models.FilterProperties{Module: "module.app_*", Type: "aws_instance", Name: "web"},
+	}}}
+	s.NoError(validateFilterConfig(filterConfig))
+
+	result, err := FilterStateResources(resources, DeleteResourceFilterFunc, filterConfig)
+	s.NoError(err)
+	s.Equal(1, len(result.Resources))
+	s.Equal("module.app_1.module.inner.aws_instance.web", result.Resources[0].Address(), "globs should not match nested modules")
+}
+
+func (s *TestSuite) TestValidateNameTemplate() {
+	cases := []struct {
+		pattern    string
+		template   string
+		errMessage string
+	}{
+		{"orig_*", "dr_$1", ""},
+		{"orig_*", "dr_${1}_east", ""},
+		{"orig_*", "dr_$$1_east", ""},
+		{"orig_*_?", "${2}_$1", ""},
+		{"re:orig_(?P<suffix>.*)", "dr_${suffix}", ""},
+		{"orig_*", "dr_$1_east", `New name "dr_$1_east" of filters[0] refers to group "1_east", which name pattern "orig_*" does not have. Use ${1} to separate a group number from the text after it`},
+		{"orig_*", "dr_$2", `New name "dr_$2" of filters[0] refers to group "2", which name pattern "orig_*" does not have. Use ${1} to separate a group number from the text after it`},
+		{"orig_name", "dr_${name}", `New name "dr_${name}" of filters[0] refers to group "name", which name pattern "orig_name" does not have`},
+	}
+
+	for _, c := range cases {
+		filterConfig := &models.FilterConfig{Filters: []models.Filter{{
+			FilterProperties: models.FilterProperties{Type: "type_1", Name: c.pattern},
+			NewProperties:    models.NewProperties{Name: c.template},
+		}}}
+		err := validateFilterConfig(filterConfig)
+		if c.errMessage == "" {
+			s.NoError(err, c.template)
+		} else {
+			s.EqualError(err, c.errMessage, c.template)
+		}
+	}
+
+	filterConfig := &models.FilterConfig{Filters: []models.Filter{{Address: "type_1.orig_*", NewProperties: models.NewProperties{Name: "$1_dr"}}}}
+	s.EqualError(validateFilterConfig(filterConfig), `New name "$1_dr" of filters[0] refers to group "1_dr", which name pattern "orig_*" does not have. Use ${1} to separate a group number from the text after it`)
+
+	filterConfig = &models.FilterConfig{Filters: []models.Filter{{
+		FilterProperties: models.FilterProperties{Type: "type_1", Name: "orig_*"},
+		NewProperties:    models.NewProperties{Name: "${1}"},
+	}}}
+	s.NoError(validateFilterConfig(filterConfig))
+	_, err := FilterStateResources([]models.Resource{{Mode: "managed", Type: "type_1", Name: "orig_"}}, CopyResourceFilterFunc, filterConfig)
+	s.EqualError(err, `Unable to filter resource type_1.orig_. Err: New name "${1}" expands to an empty name`)
+}
+
+func (s *TestSuite) TestResourceFilterFuncMode() {
+	resources := []models.Resource{
+		{Module: `module.app["east"]`, Mode: "managed", Type: "aws_ami", Name: "base"},
+		{Module: `module.app["east"]`, Mode: "data", Type: "aws_ami", Name: "base"},
+		{Module: `module.app["west"]`, Mode: "data", Type: "aws_ami", Name: "base"},
+	}
+	cases := []struct {
+		properties models.FilterProperties
+		copied     []string
+		message    string
+	}{
+		{
+			models.FilterProperties{Module: "module.app", Type: "aws_ami", Name: "base"},
+			[]string{`module.app["east"].aws_ami.base`},
+			"managed resources should be selected by default",
+		},
+		{
+			models.FilterProperties{Mode: "data", Module: "module.app", Type: "aws_ami", Name: "base"},
+			[]string{`module.app["east"].data.aws_ami.base`, `module.app["west"].data.aws_ami.base`},
+			"data sources of every module instance should be selected",
+		},
+		{
+			models.FilterProperties{Mode: "data", Module: `module.app["west"]`, Type: "aws_ami", Name: "base"},
+			[]string{`module.app["west"].data.aws_ami.base`},
+			"data sources of one module instance should be selected",
+		},
+	}
+
+	for _, c := range cases {
+		filterConfig := &models.FilterConfig{Filters: []models.Filter{{FilterProperties: c.properties}}}
+		s.NoError(validateFilterConfig(filterConfig), c.message)
+
+		copied, err := FilterStateResources(resources, CopyResourceFilterFunc, filterConfig)
+		s.NoError(err, c.message)
+		kept, err := FilterStateResources(resources, DeleteResourceFilterFunc, filterConfig)
+		s.NoError(err, c.message)
+
+		addresses := make([]string, 0)
+		for _, resource := range copied.Resources {
+			addresses = append(addresses, resource.Address())
+		}
+		s.Equal(c.copied, addresses, c.message)
+		s.Equal(len(resources)-len(c.copied), len(kept.Resources), c.message)
+		for _, resource := range kept.Resources {
+			s.NotContains(c.copied, resource.Address(), c.message)
+		}
+	}
+
+	filterConfig := &models.FilterConfig{Filters: []models.Filter{{FilterProperties: models.FilterProperties{Mode: "resource"}}}}
+	s.EqualError(validateFilterConfig(filterConfig), `Invalid mode "resource" of filters[0]. Expected managed or data`)
+}
+
+func (s *TestSuite) TestResourceFilterFuncWhere() {
+	newResource := func() *models.Resource {
+		instances := make([]models.Instance, 0)
+		for i, zone := range []string{"old", "new", "old"} {
+			instances = append(instances, models.Instance{
+				IndexKey: float64(i),
+				Attributes: map[string]interface{}{
+					"zone_id": zone,
+					"ttl":     float64(300 * (i + 1)),
+					"tags":    map[string]interface{}{"dr": fmt.Sprint(i == 1)},
+				},
+			})
+		}
+		instances[2].Attributes["records"] = []interface{}{"10.0.0.1", "10.0.0.2"}
+		return &models.Resource{Mode: "managed", Type: "aws_route53_record", Name: "www", Instances: instances}
+	}
+	instanceKeys := func(resource *models.Resource) []interface{} {
+		if resource == nil {
+			return nil
+		}
+		keys := make([]interface{}, 0)
+		for _, instance := range resource.Instances {
+			keys = append(keys, instance.IndexKey)
+		}
+		return keys
+	}
+	exists, missing := true, false
+
+	cases := []struct {
+		where   []models.Predicate
+		copied  []interface{}
+		kept    []interface{}
+		message string
+	}{
+		{[]models.Predicate{{Attribute: "zone_id", Equals: "old"}}, []interface{}{float64(0), float64(2)}, []interface{}{float64(1)}, "equals should select matching instances"},
+		{[]models.Predicate{{Attribute: "zone_id", NotEquals: "old"}}, []interface{}{float64(1)}, []interface{}{float64(0), float64(2)}, "not_equals should select other instances"},
+		{[]models.Predicate{{Attribute: "tags.dr", Equals: true}}, []interface{}{float64(1)}, []interface{}{float64(0), float64(2)}, "nested paths should be compared as strings"},
+		{[]models.Predicate{{Attribute: "ttl", Equals: 600}}, []interface{}{float64(1)}, []interface{}{float64(0), float64(2)}, "numbers should be compared"},
+		{[]models.Predicate{{Attribute: "zone_id", Regex: "ol."}}, []interface{}{float64(0), float64(2)}, []interface{}{float64(1)}, "regex should match whole values"},
+		{[]models.Predicate{{Attribute: "zone_id", Regex: "ol"}}, nil, []interface{}{float64(0), float64(1), float64(2)}, "regex should not match part of values"},
+		{[]models.Predicate{{Attribute: "records", Exists: &exists}}, []interface{}{float64(2)}, []interface{}{float64(0), float64(1)}, "exists should select instances with the attribute"},
+		{[]models.Predicate{{Attribute: "records", Exists: &missing}}, []interface{}{float64(0), float64(1)}, []interface{}{float64(2)}, "exists false should select instances without the attribute"},
+		{[]models.Predicate{{Attribute: "ttl", In: []interface{}{300, 900}}}, []interface{}{float64(0), float64(2)}, []interface{}{float64(1)}, "in should select instances with any of the values"},
+		{[]models.Predicate{{Attribute: "records[*]", Equals: "10.0.0.2"}}, []interface{}{float64(2)}, []interface{}{float64(0), float64(1)}, "wildcards should match any element"},
+		{
+			[]models.Predicate{{Attribute: "zone_id", Equals: "old"}, {Attribute: "ttl", Equals: 900}},
+			[]interface{}{float64(2)},
+			[]interface{}{float64(0), float64(1)},
+			"every predicate should match",
+		},
+		{[]models.Predicate{{Attribute: "zone_id", Equals: "none"}}, nil, []interface{}{float64(0), float64(1), float64(2)}, "no matching instance should not match the resource"},
+	}
+
+	for _, c := range cases {
+		filterConfig := &models.FilterConfig{Filters: []models.Filter{{
+			FilterProperties: models.FilterProperties{Type: "aws_route53_record", Name: "www", Where: c.where},
+		}}}
+		s.NoError(validateFilterConfig(filterConfig), c.message)
+
+		copied, err := CopyResourceFilterFunc(newResource(), filterConfig)
+		s.NoError(err, c.message)
+		s.Equal(c.copied, instanceKeys(copied), c.message)
+
+		kept, err := DeleteResourceFilterFunc(newResource(), filterConfig)
+		s.NoError(err, c.message)
+		s.Equal(c.kept, instanceKeys(kept), c.message)
+	}
+
+	filterConfig := &models.FilterConfig{Filters: []models.Filter{{
+		Address:          "aws_route53_record.www[1]",
+		FilterProperties: models.FilterProperties{Where: []models.Predicate{{Attribute: "zone_id", Equals: "new"}}},
+	}}}
+	s.NoError(validateFilterConfig(filterConfig))
+	copied, err := CopyResourceFilterFunc(newResource(), filterConfig)
+	s.NoError(err)
+	s.Equal([]interface{}{float64(1)}, instanceKeys(copied))
+
+	errCases := []struct {
+		predicate models.Predicate
+		err       string
+	}{
+		{models.Predicate{Attribute: "zone_id"}, "Invalid where predicate on zone_id. Expected one of equals, not_equals, regex, exists or in"},
+		{models.Predicate{Attribute: "zone_id", Equals: "a", Regex: "a"}, "Invalid where predicate on zone_id. Expected one of equals, not_equals, regex, exists or in"},
+		{models.Predicate{Attribute: "zone_id", Regex: "[a"}, "Invalid where predicate on zone_id. Err: Invalid pattern \"re:[a\""},
+		{models.Predicate{Attribute: "tags..dr", Equals: "a"}, "Invalid where predicate. Err: Invalid attribute path \"tags..dr\""},
+	}
+	for _, c := range errCases {
+		filterConfig.Filters[0].FilterProperties.Where = []models.Predicate{c.predicate}
+		err := validateFilterConfig(filterConfig)
+		s.Error(err, c.err)
+		if err != nil {
+			s.Contains(err.Error(), c.err)
+		}
+	}
+}
+
+func (s *TestSuite) TestResourceFilterFuncExclude() {
+	resources := append(testutils.NewStateResources(), models.Resource{
+		Mode:      "managed",
+		Type:      "type_c",
+		Name:      "name_c",
+		Instances: newInstances(float64(0), float64(1), float64(2)),
+	})
+	byProperties := func(module, typ string) models.Filter {
+		return models.Filter{FilterProperties: models.FilterProperties{Module: module, Type: typ, Name: "*"}}
+	}
+	addresses := func(resources []models.Resource) []string {
+		result := make([]string, 0)
+		for _, resource := range resources {
+			for _, instance := range resource.Instances {
+				result = append(result, resource.InstanceAddress(instance))
+			}
+			if len(resource.Instances) == 0 {
+				result = append(result, resource.Address())
+			}
+		}
+		return result
+	}
+	has := func(list []string, address string) bool {
+		for _, a := range list {
+			if a == address {
+				return true
+			}
+		}
+		return false
+	}
+	all := addresses(resources)
+	without := func(excluded ...string) []string {
+		result := make([]string, 0)
+		for _, address := range all {
+			if !has(excluded, address) {
+				result = append(result, address)
+			}
+		}
+		return result
+	}
+
+	cases := []struct {
+		filterConfig models.FilterConfig
+		copied       []string
+		message      string
+	}{
+		{
+			filterConfig: models.FilterConfig{
+				Filters: []models.Filter{byProperties("module.test_module_1", "type_1"), byProperties("module.test_module_2", "type_2")},
+				Exclude: []models.Filter{{Address: "module.test_module_1.type_1.orig_name_1"}},
+			},
+			copied:  []string{"module.test_module_2.type_2.orig_name_2"},
+			message: "excludes should take precedence over filters",
+		},
+		{
+			filterConfig: models.FilterConfig{
+				GlobalResourceTypes: []string{"aws_iam_*"},
+				Exclude:             []models.Filter{byProperties("re:.*", "aws_iam_policy")},
+			},
+			copied: []string{
+				"module.test_global_module_2.aws_iam_access_key.global_orig_name_2",
+				"module.test_global_module_3.aws_iam_policy_document.global_orig_name_3",
+			},
+			message: "excludes should take precedence over global resource types",
+		},
+		{
+			filterConfig: models.FilterConfig{
+				Mode:    models.FilterModeExclude,
+				Exclude: []models.Filter{byProperties("module.test_module_1", "type_1"), {Address: "type_c.name_c[1]"}},
+			},
+			copied:  without("module.test_module_1.type_1.orig_name_1", "type_c.name_c[1]"),
+			message: "exclude mode should select every resource but the excluded ones",
+		},
+		{
+			filterConfig: models.FilterConfig{
+				Mode: models.FilterModeExclude,
+				Filters: []models.Filter{{
+					Address:       "type_c.name_c[2]",
+					NewProperties: models.NewProperties{Attributes: map[string]interface{}{"attr1": "new"}},
+				}},
+			},
+			copied:  all,
+			message: "exclude mode should keep instances no filter selects",
+		},
+	}
+
+	for _, c := range cases {
+		s.NoError(validateFilterConfig(&c.filterConfig), c.message)
+
+		copied, err := FilterStateResources(resources, CopyResourceFilterFunc, &c.filterConfig)
+		s.NoError(err, c.message)
+		s.Equal(c.copied, addresses(copied.Resources), c.message)
+
+		kept, err := FilterStateResources(resources, DeleteResourceFilterFunc, &c.filterConfig)
+		s.NoError(err, c.message)
+		expectedKept := make([]string, 0)
+		for _, address := range all {
+			if !has(c.copied, address) {
+				expectedKept = append(expectedKept, address)
+			}
+		}
+		s.Equal(expectedKept, addresses(kept.Resources), c.message)
+	}
+
+	filterConfig := &models.FilterConfig{Mode: "all"}
+	s.EqualError(validateFilterConfig(filterConfig), `Invalid mode "all". Expected include or exclude`)
+	filterConfig = &models.FilterConfig{Exclude: []models.Filter{{Address: "type_c.name_c", NewProperties: models.NewProperties{Name: "new"}}}}
+	s.EqualError(validateFilterConfig(filterConfig), "exclude[0] sets new_properties. Excluded resources cannot be changed")
+	filterConfig = &models.FilterConfig{Exclude: []models.Filter{{Address: "type_c"}}}
+	s.Error(validateFilterConfig(filterConfig))
+}
+
+func (s *TestSuite) TestResourceFilterFuncAddress() {
+	newResource := func() *models.Resource {
+		return &models.Resource{Module: `module.app["east"]`, Mode: "managed", Type: "type_a", Name: "name_a", Instances: newInstances("a", "b", "c")}
+	}
+	instanceKeys := func(resource *models.Resource) []interface{} {
+		if resource == nil {
+			return nil
+		}
+		keys := make([]interface{}, 0)
+		for _, instance := range resource.Instances {
+			keys = append(keys, instance.IndexKey)
+		}
+		return keys
+	}
+
+	cases := []struct {
+		addresses []string
+		copied    []interface{}
+		kept      []interface{}
+		message   string
+	}{
+		{[]string{`module.app["east"].type_a.name_a`}, []interface{}{"a", "b", "c"}, nil, "resource address should select every instance"},
+		{[]string{`module.app["east"].type_a.name_a["b"]`}, []interface{}{"b"}, []interface{}{"a", "c"}, "instance address should select one instance"},
+		{[]string{`module.app["east"].type_a.name_a["c"]`, `module.app["east"].type_a.name_a["a"]`}, []interface{}{"a", "c"}, []interface{}{"b"}, "instance addresses should add up"},
+		{[]string{`module.app["east"].type_a.name_a["d"]`}, nil, []interface{}{"a", "b", "c"}, "missing instance should not match"},
+		{[]string{`module.app["west"].type_a.name_a`}, nil, []interface{}{"a", "b", "c"}, "other module instance should not match"},
+		{[]string{`module.app["east"].data.type_a.name_a`}, nil, []interface{}{"a", "b", "c"}, "data address should not match managed resource"},
+		{[]string{`module.app*.type_a.*`}, []interface{}{"a", "b", "c"}, nil, "wildcards should match"},
+	}
+
+	for _, c := range cases {
+		filterConfig := &models.FilterConfig{}
+		for _, address := range c.addresses {
+			filterConfig.Filters = append(filterConfig.Filters, models.Filter{Address: address})
+		}
+		s.NoError(validateFilterConfig(filterConfig), c.message)
+
+		copied, err := CopyResourceFilterFunc(newResource(), filterConfig)
+		s.NoError(err, c.message)
+		s.Equal(c.copied, instanceKeys(copied), c.message)
+
+		kept, err := DeleteResourceFilterFunc(newResource(), filterConfig)
+		s.NoError(err, c.message)
+		s.Equal(c.kept, instanceKeys(kept), c.message)
+	}
+
+	data := &models.Resource{Mode: "data", Type: "aws_ami", Name: "base"}
+	filterConfig := &models.FilterConfig{Filters: []models.Filter{{Address: "data.aws_ami.base", NewProperties: models.NewProperties{Name: "dr"}}}}
+	copied, err := CopyResourceFilterFunc(data, filterConfig)
+	s.NoError(err)
+	s.Equal("data.aws_ami.dr", copied.Address())
+
+	filterConfig.Filters[0].FilterProperties.Type = "aws_ami"
+	s.EqualError(validateFilterConfig(filterConfig), "filters[0] sets both address and filter_properties")
+	filterConfig.Filters[0] = models.Filter{Address: "data.aws_ami"}
+	s.Error(validateFilterConfig(filterConfig))
+}
+
+func (s *TestSuite) TestCopyResourceFilterFuncConflictingFilters() {
+	newResource := func() *models.Resource {
+		instances := newInstances(float64(0), float64(1))
+		instances[1].Attributes["attr1"] = "new_value_1"
+		return &models.Resource{Mode: "managed", Type: "aws_instance", Name: "web", Instances: instances}
+	}
+	filterFor := func(address string, name string, provider string) models.Filter {
+		return models.Filter{Address: address, NewProperties: models.NewProperties{Name: name, Provider: provider}}
+	}
+	whereFilter := func(value string, name string) models.Filter {
+		return models.Filter{
+			FilterProperties: models.FilterProperties{Type: "aws_instance", Name: "web", Where: []models.Predicate{{Attribute: "attr1", Equals: value}}},
+			NewProperties:    models.NewProperties{Name: name},
+		}
+	}
+
+	cases := []struct {
+		filters    []models.Filter
+		expected   string
+		errMessage string
+		message    string
+	}{
+		{[]models.Filter{filterFor("aws_instance.web[0]", "web_a", ""), filterFor("aws_instance.web[1]", "web_a", "")}, "aws_instance.web_a", "",
+			"instance filters with the same name should be copied to one resource"},
+		{[]models.Filter{filterFor("aws_instance.web", "primary", ""), filterFor("aws_instance.*", "", "")}, "aws_instance.primary", "",
+			"filters shadowed by a filter selecting every instance should be ignored"},
+		{[]models.Filter{filterFor("aws_instance.web[5]", "web_b", ""), filterFor("aws_instance.web", "web_a", "")}, "aws_instance.web_a", "",
+			"filters selecting no instances should be ignored"},
+		{[]models.Filter{filterFor("aws_instance.web[0]", "web_a", ""), filterFor("aws_instance.web[1]", "web_b", "")}, "",
+			"filter aws_instance.web[0] and filter aws_instance.web[1] copy instances of aws_instance.web with different names web_a and web_b",
+			"instance filters with different names should return an error"},
+		{[]models.Filter{whereFilter("old_value_1", "web_a"), whereFilter("new_value_1", "web_b")}, "",
+			`filter (module "", type "aws_instance", name "web") and filter (module "", type "aws_instance", name "web") copy instances of aws_instance.web with different names web_a and web_b`,
+			"where filters with different names should return an error"},
+		{[]models.Filter{filterFor("aws_instance.web[0]", "", "aws.east"), filterFor("aws_instance.web[1]", "", "aws.west")}, "",
+			`filter aws_instance.web[0] and filter aws_instance.web[1] copy instances of aws_instance.web with different providers "aws.east" and "aws.west"`,
+			"instance filters with different providers should return an error"},
+	}
+
+	for _, c := range cases {
+		result, err := CopyResourceFilterFunc(newResource(), &models.FilterConfig{Filters: c.filters})
+		if c.errMessage != "" {
+			s.EqualError(err, c.errMessage, c.message)
+			continue
+		}
+		s.NoError(err, c.message)
+		s.Equal(c.expected, result.Address(), c.message)
+		s.Len(result.Instances, 2, c.message)
+	}
+}
+
+func (s *TestSuite) TestCopyResourceFilterFuncNestedAttributes() {
+	resource := &models.Resource{
+		Module: "module.a",
+		Mode:   "managed",
+		Type:   "type_a",
+		Name:   "name_a",
+		Instances: []models.Instance{
+			{
+				IndexKey: "east",
+				Attributes: map[string]interface{}{
+					"tags": map[string]interface{}{"Environment": "prod"},
+					"vpc_config": []interface{}{
+						map[string]interface{}{"subnet_ids": []interface{}{"subnet-1"}},
+					},
+					"ingress": []interface{}{
+						map[string]interface{}{"cidr_blocks": []interface{}{"10.0.0.0/16"}},
+						map[string]interface{}{"cidr_blocks": []interface{}{"10.1.0.0/16"}},
+					},
+				},
+			},
+		},
+	}
+	filterConfig := &models.FilterConfig{
+		Filters: []models.Filter{
+			{
+				FilterProperties: models.FilterProperties{Module: "module.a", Type: "type_a", Name: "name_a"},
+				NewProperties: models.NewProperties{
+					Attributes: map[string]interface{}{
+						"tags.Environment":         "dr",
+						"vpc_config[0].subnet_ids": []interface{}{"subnet-2"},
+						"ingress[*].cidr_blocks":   []interface{}{"10.2.0.0/16"},
+					},
+				},
+			},
+		},
+	}
+
+	result, err := CopyResourceFilterFunc(resource, filterConfig)
+	s.NoError(err)
+	attributes := result.Instances[0].Attributes
+	s.Equal("dr", attributes["tags"].(map[string]interface{})["Environment"])
+	s.Equal([]interface{}{"subnet-2"}, attributes["vpc_config"].([]interface{})[0].(map[string]interface{})["subnet_ids"])
+	for _, ingress := range attributes["ingress"].([]interface{}) {
+		s.Equal([]interface{}{"10.2.0.0/16"}, ingress.(map[string]interface{})["cidr_blocks"])
+	}
+
+	filterConfig.Filters[0].NewProperties.Attributes = map[string]interface{}{"tags.Owner": "dr"}
+	result, err = CopyResourceFilterFunc(resource, filterConfig)
+	s.Error(err)
+	s.Nil(result)
+	s.Equal(`Unable to override attribute of module.a.type_a.name_a["east"]. Err: Attribute tags.Owner does not exist`, err.Error())
+}
+
+func (s *TestSuite) TestCopyStateFilterMissingAttribute() {
+	var res = testutils.NewStateResources()
+
+	fr, err := StateFilter(res, CopyResourceFilterFunc, "./testdata/missingAttributeFilterConfig.json")
+	s.Error(err)
+	s.Contains(err.Error(), "Unable to filter resource module.test_module_2.type_2.orig_name_2.")
+	s.Contains(err.Error(), "Attribute attr3 does not exist")
+	s.Nil(fr)
+}
+
+func (s *TestSuite) TestApplyReplacements() {
+	resources := []models.Resource{
+		{
+			Type: "aws_lb",
+			Instances: []models.Instance{
+				{
+					Attributes: map[string]interface{}{
+						"arn":    "arn:aws:elasticloadbalancing:us-east-1:111111111111:loadbalancer/app/a",
+						"region": "us-east-1",
+						"port":   float64(443),
+						"tags":   map[string]interface{}{"Region": "us-east-1"},
+						"subnets": []interface{}{
+							"subnet-1",
+						},
+					},
+				},
+			},
+		},
+		{
+			Type: "aws_iam_role",
+			Instances: []models.Instance{
+				{Attributes: map[string]interface{}{"arn": "arn:aws:iam::111111111111:role/us-east-1"}},
+			},
+		},
+	}
+	replacements := []models.Replacement{
+		{Find: "us-east-1", Replace: "us-west-2", ResourceTypes: []string{"aws_lb*"}},
+		{Find: `:(\d{12}):`, Replace: ":222222222222:", Regex: true},
+		{Find: "not-found", Replace: "found"},
+	}
+
+	counts, err := ApplyReplacements(resources, replacements)
+	s.NoError(err)
+	s.Equal([]int{3, 2, 0}, counts)
+	s.Equal("arn:aws:elasticloadbalancing:us-west-2:222222222222:loadbalancer/app/a", resources[0].Instances[0].Attributes["arn"])
+	s.Equal("us-west-2", resources[0].Instances[0].Attributes["region"])
+	s.Equal(float64(443), resources[0].Instances[0].Attributes["port"])
+	s.Equal(map[string]interface{}{"Region": "us-west-2"}, resources[0].Instances[0].Attributes["tags"])
+	s.Equal("arn:aws:iam::222222222222:role/us-east-1", resources[1].Instances[0].Attributes["arn"])
+
+	_, err = ApplyReplacements(resources, []models.Replacement{{Find: "(", Regex: true}})
+	s.Error(err)
+}
+
+func (s *TestSuite) TestParseProviderAddress() {
+	cases := []struct {
+		address  string
+		expected string
+		err      bool
+	}{
+		{`provider["registry.terraform.io/hashicorp/aws"]`, `provider["registry.terraform.io/hashicorp/aws"]`, false},
+		{`provider["registry.terraform.io/hashicorp/aws"].dr`, `provider["registry.terraform.io/hashicorp/aws"].dr`, false},
+		{"provider.aws", `provider["registry.terraform.io/hashicorp/aws"]`, false},
+		{"provider.aws.dr", `provider["registry.terraform.io/hashicorp/aws"].dr`, false},
+		{"aws", `provider["registry.terraform.io/hashicorp/aws"]`, false},
+		{"aws.dr", `provider["registry.terraform.io/hashicorp/aws"].dr`, false},
+		{"mycorp/aws.dr", `provider["registry.terraform.io/mycorp/aws"].dr`, false},
+		{"tfe.example.com/mycorp/aws", `provider["tfe.example.com/mycorp/aws"]`, false},
+		{"", "", true},
+		{"a/b/c/d", "", true},
+	}
+
+	for _, c := range cases {
+		p, err := parseProviderAddress(c.address)
+		if c.err {
+			s.Error(err, c.address)
+		} else {
+			s.NoError(err, c.address)
+			s.Equal(c.expected, p.String(), c.address)
+		}
+	}
+}
+
+func (s *TestSuite) TestCopyResourceFilterFuncProvider() {
+	aws := `provider["registry.terraform.io/hashicorp/aws"]`
+	filterConfig := &models.FilterConfig{
+		GlobalResourceTypes: []string{"aws_iam_role"},
+		Filters: []models.Filter{
+			{FilterProperties: models.FilterProperties{Type: "aws_s3_bucket", Name: "a"}},
+			{
+				FilterProperties: models.FilterProperties{Type: "aws_s3_bucket", Name: "b"},
+				NewProperties:    models.NewProperties{Provider: "mycorp/aws"},
+			},
+			{FilterProperties: models.FilterProperties{Type: "aws_s3_bucket", Name: "c"}},
+		},
+		Providers: map[string]string{
+			"aws":               "aws.dr",
+			"provider.aws.west": `provider["registry.terraform.io/hashicorp/aws"].east`,
+		},
+	}
+	cases := []struct {
+		resource models.Resource
+		expected string
+	}{
+		{models.Resource{Mode: "managed", Type: "aws_iam_role", Name: "r", Provider: aws}, aws + ".dr"},
+		{models.Resource{Mode: "managed", Type: "aws_s3_bucket", Name: "a", Provider: aws}, aws + ".dr"},
+		{models.Resource{Mode: "managed", Type: "aws_s3_bucket", Name: "b", Provider: aws}, `provider["registry.terraform.io/mycorp/aws"]`},
+		{models.Resource{Mode: "managed", Type: "aws_s3_bucket", Name: "c", Provider: aws + ".west"}, aws + ".east"},
+		{models.Resource{Mode: "managed", Type: "aws_s3_bucket", Name: "c", Provider: aws + ".other"}, aws + ".other"},
+	}
+
+	for _, c := range cases {
+		resource := c.resource
+		result, err := CopyResourceFilterFunc(&resource, filterConfig)
+		s.NoError(err)
+		s.Equal(c.expected, result.Provider, c.resource.Address())
+	}
+
+	filterConfig.Providers["provider.aws"] = "aws.west"
+	s.Error(validateFilterConfig(filterConfig))
+}
+
+func (s *TestSuite) TestFilterStateResourcesDependencies() {
+	res := testutils.NewStateResources()
+	res[1].Instances[0].Dependencies = []string{
+		"module.test_module_2.type_2.orig_name_2",
+		"module.test_module_3.type_3.orig_name_3",
+	}
+	res[2].Instances[0].Dependencies = []string{
+		"module.test_module_1.type_1.orig_name_1",
+		"module.test_global_module_0.aws_cloudfront_distribution.global_orig_name_0",
+	}
+	res[3].Instances[0].Dependencies = []string{
+		"module.test_module_1.type_1.orig_name_1",
+	}
+
+	filterConfig, err := ReadFilterConfig("./testdata/filterConfig.json")
+	s.NoError(err)
+	result, err := FilterStateResources(res, CopyResourceFilterFunc, filterConfig)
+	s.NoError(err)
+	s.Equal([]string{
+		"module.test_module_2.type_2.orig_name_2",
+	}, get(result.Resources, "module.test_module_1", "managed", "type_1").Instances[0].Dependencies)
+	s.Equal([]string{
+		"module.test_module_1.type_1.new_name_1",
+		"module.test_global_module_0.aws_cloudfront_distribution.global_orig_name_0",
+	}, get(result.Resources, "module.test_module_2", "managed", "type_2").Instances[0].Dependencies)
+
+	result, err = FilterStateResources(res, DeleteResourceFilterFunc, filterConfig)
+	s.NoError(err)
+	s.Equal([]string{}, get(result.Resources, "module.test_module_3", "managed", "type_3").Instances[0].Dependencies)
+	s.Nil(get(result.Resources, "module.test_module_4", "managed", "type_4").Instances[0].Dependencies)
+}
+
+func (s *TestSuite) TestFilterOutputs() {
+	cases := []struct {
+		outputFilter models.OutputFilter
+		expected     map[string]interface{}
+		renames      map[string]string
+		err          string
+		message      string
+	}{
+		{
+			outputFilter: models.OutputFilter{},
+			expected:     map[string]interface{}{"bucket_name": "bucket", "db_password": "password", "subnet_ids": []interface{}{"subnet-1", "subnet-2"}},
+			renames:      map[string]string{},
+			message:      "every output should be copied by default",
+		},
+		{
+			outputFilter: models.OutputFilter{
+				Include: []string{"bucket_*", "subnet_ids"},
+				Exclude: []string{"subnet_*"},
+				Rename:  map[string]string{"bucket_name": "dr_bucket_name"},
+				Values:  map[string]interface{}{"bucket_name": "dr-bucket"},
+			},
+			expected: map[string]interface{}{"dr_bucket_name": "dr-bucket"},
+			renames:  map[string]string{"bucket_name": "dr_bucket_name"},
+			message:  "outputs should be included, excluded, renamed and overridden",
+		},
+		{
+			outputFilter: models.OutputFilter{Rename: map[string]string{"not_found": "found"}},
+			err:          "Unable to rename output not_found. Output does not exist",
+			message:      "renaming a missing output should fail",
+		},
+		{
+			outputFilter: models.OutputFilter{Values: map[string]interface{}{"not_found": "found"}},
+			err:          "Unable to override output not_found. Output does not exist",
+			message:      "overriding a missing output should fail",
+		},
+	}
+
+	for _, c := range cases {
+		outputs, renames, err := FilterOutputs(testutils.NewStateOutputs(), c.outputFilter)
+		if c.err != "" {
+			s.EqualError(err, c.err, c.message)
+			continue
+		}
+		s.NoError(err, c.message)
+		s.Equal(c.renames, renames, c.message)
+		values := make(map[string]interface{})
+		for name, output := range outputs {
+			values[name] = output.Value
+		}
+		s.Equal(c.expected, values, c.message)
+	}
+	s.True(testutils.NewStateOutputs()["db_password"].Sensitive)
+}
+
+func (s *TestSuite) TestLint() {
+	resources := append(testutils.NewStateResources(), models.Resource{
+		Module:    "module.test_module_1",
+		Mode:      "managed",
+		Type:      "type_1",
+		Name:      "orig_name_x",
+		Instances: newInstances(float64(0), float64(1)),
+	})
+	filterFor := func(name string, newName string) models.Filter {
+		return models.Filter{
+			FilterProperties: models.FilterProperties{Module: "module.test_module_1", Type: "type_1", Name: name},
+			NewProperties:    models.NewProperties{Name: newName},
+		}
+	}
+
+	cases := []struct {
+		filterConfig models.FilterConfig
+		errors       int
+		warnings     int
+		selected     int
+		findings     []string
+		message      string
+	}{
+		{
+			filterConfig: models.FilterConfig{
+				GlobalResourceTypes: append([]string{"aws_route53_record"}, testutils.GlobalResources...),
+				Filters:             []models.Filter{filterFor("orig_name_1", "new_name_1")},
+			},
+			warnings: 1,
+			selected: len(testutils.GlobalResources) + 1,
+			findings: []string{`warning: global_resource_types[0]: global resource type "aws_route53_record" does not match any resource in the state`},
+			message:  "global resource types without resources should be warnings",
+		},
+		{
+			filterConfig: models.FilterConfig{Filters: []models.Filter{filterFor("not_found", "")}},
+			errors:       1,
+			findings:     []string{`error: filters[0]: filter (module "module.test_module_1", type "type_1", name "not_found") does not match any resource`},
+			message:      "filters without resources should be errors",
+		},
+		{
+			filterConfig: models.FilterConfig{
+				GlobalResourceTypes: []string{"type_1"},
+				Filters:             []models.Filter{filterFor("orig_name_1", "")},
+			},
+			errors:   1,
+			selected: 2,
+			findings: []string{`error: filters[0]: filter (module "module.test_module_1", type "type_1", name "orig_name_1") only matches resources that are excluded or selected by an earlier filter or global resource type`},
+			message:  "filters shadowed by global resource types should be errors",
+		},
+		{
+			filterConfig: models.FilterConfig{
+				Filters: []models.Filter{filterFor("orig_name_1", "")},
+				Exclude: []models.Filter{{Address: "module.test_module_1.type_1.orig_name_1"}, {Address: "type_1.not_found"}},
+			},
+			errors: 2,
+			findings: []string{
+				`error: exclude[1]: filter type_1.not_found does not match any resource`,
+				`error: filters[0]: filter (module "module.test_module_1", type "type_1", name "orig_name_1") only matches resources that are excluded`,
+			},
+			message: "excludes without resources and filters of excluded resources should be errors",
+		},
+		{
+			filterConfig: models.FilterConfig{Filters: []models.Filter{filterFor("orig_name_*", "")}},
+			warnings:     1,
+			selected:     2,
+			findings:     []string{`warning: filters[0]: filter (module "module.test_module_1", type "type_1", name "orig_name_*") matches 2 resources`},
+			message:      "filters matching many resources should be warnings",
+		},
+		{
+			filterConfig: models.FilterConfig{Filters: []models.Filter{filterFor("orig_name_1", "orig_name_x")}},
+			errors:       1,
+			selected:     1,
+			findings:     []string{"error: module.test_module_1.type_1.orig_name_1 is renamed to module.test_module_1.type_1.orig_name_x, which already exists in the state"},
+			message:      "renames to existing addresses should be errors",
+		},
+		{
+			filterConfig: models.FilterConfig{Filters: []models.Filter{filterFor("orig_name_*", "dr")}},
+			errors:       1,
+			warnings:     1,
+			selected:     2,
+			findings:     []string{"error: 2 copied resources have the address module.test_module_1.type_1.dr"},
+			message:      "renames of many resources to one address should be errors",
+		},
+		{
+			filterConfig: models.FilterConfig{Filters: []models.Filter{{
+				FilterProperties: models.FilterProperties{Module: "module.test_module_1", Type: "type_1", Name: "orig_name_1"},
+				NewProperties:    models.NewProperties{Attributes: map[string]interface{}{"missing": "value"}},
+			}}},
+			errors:   1,
+			findings: []string{"error: Unable to filter resource module.test_module_1.type_1.orig_name_1."},
+			message:  "resources that cannot be filtered should be errors",
+		},
+		{
+			filterConfig: models.FilterConfig{Filters: []models.Filter{
+				{Address: "module.test_module_1.type_1.orig_name_x[0]", NewProperties: models.NewProperties{Name: "new_name_a"}},
+				{Address: "module.test_module_1.type_1.orig_name_x[1]", NewProperties: models.NewProperties{Name: "new_name_b"}},
+			}},
+			errors:   1,
+			findings: []string{"error: Unable to filter resource module.test_module_1.type_1.orig_name_x. Err: filter module.test_module_1.type_1.orig_name_x[0] and filter module.test_module_1.type_1.orig_name_x[1] copy instances of module.test_module_1.type_1.orig_name_x with different names new_name_a and new_name_b"},
+			message:  "filters copying one resource with different names should be errors",
+		},
+	}
+
+	for _, c := range cases {
+		report := Lint(resources, &c.filterConfig)
+		s.Equal(c.errors, report.Errors, c.message)
+		s.Equal(c.warnings, report.Warnings, c.message)
+		s.Equal(c.selected, report.Selected, c.message)
+		s.Equal(len(resources), report.Resources, c.message)
+
+		var buf bytes.Buffer
+		report.Write(&buf)
+		for _, finding := range c.findings {
+			s.Contains(buf.String(), finding, c.message)
+		}
+		s.Contains(buf.String(), fmt.Sprintf("%d of %d resources selected, %d errors, %d warnings", c.selected, len(resources), c.errors, c.warnings), c.message)
+	}
+}
+
+func (s *TestSuite) TestReadFilterConfigInclude() {
+	os.Setenv("TFDR_TEST_REGION", "us-west-2")
+	defer os.Unsetenv("TFDR_TEST_REGION")
+
+	filterConfig, err := ReadFilterConfigWithVars("./testdata/include/main.yaml", map[string]string{"prefix": "dr"})
+	s.Require().NoError(err)
+	s.Equal([]string{"aws_iam_role", "aws_route53_record"}, filterConfig.GlobalResourceTypes)
+	s.Equal(2, len(filterConfig.Filters))
+	s.Equal("dr_name_1", filterConfig.Filters[0].NewProperties.Name)
+	s.Equal(map[string]interface{}{"attr1": "us-west-2", "attr2": "${var.prefix}"}, filterConfig.Filters[0].NewProperties.Attributes)
+	s.Equal("module.test_module_2", filterConfig.Filters[1].FilterProperties.Module)
+	s.Equal("us-west-2", filterConfig.Replacements[0].Replace)
+	s.Equal(map[string]string{"aws": "aws.dr", "google": "google.dr"}, filterConfig.Providers)
+}
+
+func (s *TestSuite) TestReadFilterConfigIncludeErrors() {
+	cases := []struct {
+		fileName   string
+		vars       map[string]string
+		errMessage string
+	}{
+		{
+			fileName:   "./testdata/include/main.yaml",
+			vars:       map[string]string{"prefix": "dr"},
+			errMessage: `testdata/include/main.yaml:12:16: Environment variable "TFDR_TEST_REGION" is not defined`,
+		},
+		{
+			fileName:   "./testdata/include/undefinedVar.json",
+			errMessage: `testdata/include/undefinedVar.json:5:27: Variable "module" is not defined`,
+		},
+		{
+			fileName:   "./testdata/include/cycleA.json",
+			errMessage: "Include cycle: ./testdata/include/cycleA.json -> testdata/include/cycleB.hcl -> testdata/include/cycleA.json",
+		},
+		{
+			fileName:   "./testdata/include/missingInclude.json",
+			errMessage: "testdata/include/missingInclude.json:3:9: Unable to include not-found.json. Err: Unable to read file.",
+		},
+	}
+
+	for _, c := range cases {
+		filterConfig, err := ReadFilterConfigWithVars(c.fileName, c.vars)
+		s.Error(err, c.fileName)
+		if err != nil {
+			s.Contains(err.Error(), c.errMessage, c.fileName)
+		}
+		s.Nil(filterConfig)
+	}
+}
+
+func (s *TestSuite) TestReadVars() {
+	vars, err := ReadVars([]string{"./testdata/include/vars.yaml"}, []string{"region=us-west-2", "prefix=dr2", "query=a=b"})
+	s.NoError(err)
+	s.Equal(map[string]string{"prefix": "dr2", "count": "2", "region": "us-west-2", "query": "a=b"}, vars)
+
+	_, err = ReadVars(nil, []string{"region"})
+	s.EqualError(err, `Invalid variable "region". Expected <name>=<value>`)
+	_, err = ReadVars([]string{"./testdata/not-found.yaml"}, nil)
+	s.Error(err)
+	_, err = ReadVars([]string{"./testdata/filterConfig.yaml"}, nil)
+	s.Error(err)
+}
@@ -0,0 +1,60 @@
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/mupuri/go-tfdr/internal/attrpath"
+	"github.com/mupuri/go-tfdr/internal/models"
+)
+
+// setAttributes applies the attribute overrides in newProperties to instance of resource, unless
+// newProperties limits the overrides to other instances. Override keys are attribute paths; see attrpath.Parse.
+func setAttributes(resource *models.Resource, instance *models.Instance, newProperties models.NewProperties) error {
+	if len(newProperties.IndexKeys) > 0 && !containsIndexKey(newProperties.IndexKeys, instance.IndexKey) {
+		return nil
+	}
+
+	paths := make([]string, 0, len(newProperties.Attributes))
+	for path := range newProperties.Attributes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		p, err := attrpath.Parse(path)
+		if err != nil {
+			return err
+		}
+		value := newProperties.Attributes[path]
+		err = attrpath.Set(instance.Attributes, p, func() interface{} { return copyValue(value) })
+		if err != nil {
+			return fmt.Errorf("Unable to override attribute of %s. Err: %v", resource.InstanceAddress(*instance), err)
+		}
+	}
+	return nil
+}
+
+func containsIndexKey(indexKeys []interface{}, indexKey interface{}) bool {
+	for _, k := range indexKeys {
+		if reflect.DeepEqual(normalizeIndexKey(k), normalizeIndexKey(indexKey)) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeIndexKey converts numeric count indexes to float64, the type json uses when decoding state
+func normalizeIndexKey(indexKey interface{}) interface{} {
+	switch k := indexKey.(type) {
+	case int:
+		return float64(k)
+	case int64:
+		return float64(k)
+	case float32:
+		return float64(k)
+	default:
+		return indexKey
+	}
+}
@@ -0,0 +1,110 @@
+package filter
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mupuri/go-tfdr/internal/document"
+)
+
+// interpolation matches ${var.<name>} and ${env.<NAME>}, optionally escaped as $${...}. Other ${...}
+// sequences, such as the regular expression groups of replacements, are left alone.
+var interpolation = regexp.MustCompile(`\$(\$?)\{(var|env)\.([^}]*)\}`)
+
+// interpolate replaces ${var.<name>} with the value of variable name and ${env.<NAME>} with the value
+// of environment variable NAME in every key and string value of node
+func interpolate(node *document.Node, vars map[string]string) error {
+	switch node.Kind {
+	case document.Map:
+		for i := range node.Entries {
+			entry := &node.Entries[i]
+			key, err := interpolateString(entry.Key, entry.KeyPos, vars)
+			if err != nil {
+				return err
+			}
+			entry.Key = key
+			if err := interpolate(entry.Value, vars); err != nil {
+				return err
+			}
+		}
+	case document.List:
+		for _, item := range node.Items {
+			if err := interpolate(item, vars); err != nil {
+				return err
+			}
+		}
+	case document.Scalar:
+		if s, ok := node.Value.(string); ok {
+			value, err := interpolateString(s, node.Pos, vars)
+			if err != nil {
+				return err
+			}
+			node.Value = value
+		}
+	}
+	return nil
+}
+
+func interpolateString(s string, pos document.Pos, vars map[string]string) (string, error) {
+	var err error
+	result := interpolation.ReplaceAllStringFunc(s, func(match string) string {
+		groups := interpolation.FindStringSubmatch(match)
+		if groups[1] != "" {
+			return strings.TrimPrefix(match, "$")
+		}
+		namespace, name := groups[2], groups[3]
+		var value string
+		var ok bool
+		if namespace == "var" {
+			value, ok = vars[name]
+		} else {
+			value, ok = os.LookupEnv(name)
+		}
+		if !ok && err == nil {
+			kind := "Variable"
+			if namespace == "env" {
+				kind = "Environment variable"
+			}
+			err = &document.Error{Pos: pos, Msg: fmt.Sprintf("%s %q is not defined", kind, name)}
+		}
+		return value
+	})
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+// ReadVars reads the variables of the var files and of vars, which are written as <name>=<value>.
+// Var files are JSON, YAML or HCL documents of names and values. Later files take precedence and
+// vars take precedence over files.
+func ReadVars(varFiles []string, vars []string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, varFile := range varFiles {
+		node, err := document.ParseFile(varFile)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read var file %s. Err: %v", varFile, err)
+		}
+		var values map[string]interface{}
+		if err := document.Decode(node, &values); err != nil {
+			return nil, fmt.Errorf("Unable to read var file %s. Err: %v", varFile, err)
+		}
+		for _, entry := range node.Entries {
+			if entry.Value.Kind != document.Scalar {
+				return nil, fmt.Errorf("Unable to read var file %s. Err: %v", varFile,
+					&document.Error{Pos: entry.Value.Pos, Msg: fmt.Sprintf("value of %s must be a string, number or boolean", entry.Key)})
+			}
+			result[entry.Key] = fmt.Sprint(entry.Value.Value)
+		}
+	}
+	for _, v := range vars {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("Invalid variable %q. Expected <name>=<value>", v)
+		}
+		result[parts[0]] = parts[1]
+	}
+	return result, nil
+}
@@ -0,0 +1,222 @@
+package filter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/mupuri/go-tfdr/internal/models"
+)
+
+// Severity &
+type Severity string
+
+// Severities of findings. Only errors make a filter config invalid.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a problem with a filter config found by checking it against a state
+type Finding struct {
+	Severity Severity `json:"severity"`
+	// Location is the part of the filter config the finding is about, e.g. filters[1]
+	Location  string   `json:"location,omitempty"`
+	Message   string   `json:"message"`
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// Report lists the findings of checking a filter config against the resources of a state
+type Report struct {
+	Resources int `json:"resources"`
+	// Selected is the number of resources the filter config would copy
+	Selected int       `json:"selected"`
+	Errors   int       `json:"errors"`
+	Warnings int       `json:"warnings"`
+	Findings []Finding `json:"findings"`
+}
+
+func (r *Report) add(finding Finding) {
+	if finding.Severity == SeverityError {
+		r.Errors++
+	} else {
+		r.Warnings++
+	}
+	r.Findings = append(r.Findings, finding)
+}
+
+// Lint checks filterConfig against the resources of a state. Filters are checked the way
+// CopyResourceFilterFunc applies them, so a resource counts towards the first global resource type or
+// filter that selects it. It reports filters and excludes that select no resources, filters that select
+// more than one, global resource types without resources, renames that collide with the address of
+// another resource and filters that copy instances of one resource with different names or providers.
+func Lint(resources []models.Resource, filterConfig *models.FilterConfig) *Report {
+	report := &Report{Resources: len(resources), Findings: make([]Finding, 0)}
+
+	globalMatches := make([][]string, len(filterConfig.GlobalResourceTypes))
+	filterMatches := make([][]string, len(filterConfig.Filters))
+	shadowed := make([][]string, len(filterConfig.Filters))
+	excludeMatches := make([]int, len(filterConfig.Exclude))
+	for _, resource := range resources {
+		// excluded resources count as selected so filters that only match them are reported
+		selected := false
+		for i, filter := range filterConfig.Exclude {
+			if sel := filterSelector(filter); sel.match(&resource) {
+				excludeMatches[i]++
+				selected = selected || !sel.selectsInstances()
+			}
+		}
+		global := false
+		for i, globalResource := range filterConfig.GlobalResourceTypes {
+			if !global && matchPattern(globalResource, resource.Type) {
+				globalMatches[i] = append(globalMatches[i], resource.Address())
+				global = true
+			}
+		}
+		selected = selected || global
+		for i, filter := range filterConfig.Filters {
+			sel := filterSelector(filter)
+			if !sel.match(&resource) {
+				continue
+			}
+			address := resource.Address()
+			if sel.hasIndexKey {
+				address += formatIndexKey(sel.indexKey)
+			}
+			if selected {
+				shadowed[i] = append(shadowed[i], address)
+				continue
+			}
+			filterMatches[i] = append(filterMatches[i], address)
+			// filters selecting some instances leave the other instances to later filters
+			selected = !sel.selectsInstances()
+		}
+	}
+
+	for i, globalResource := range filterConfig.GlobalResourceTypes {
+		if len(globalMatches[i]) == 0 {
+			report.add(Finding{
+				Severity: SeverityWarning,
+				Location: fmt.Sprintf("global_resource_types[%d]", i),
+				Message:  fmt.Sprintf("global resource type %q does not match any resource in the state", globalResource),
+			})
+		}
+	}
+	for i, filter := range filterConfig.Exclude {
+		if excludeMatches[i] == 0 {
+			report.add(Finding{
+				Severity: SeverityError,
+				Location: fmt.Sprintf("exclude[%d]", i),
+				Message:  fmt.Sprintf("%s does not match any resource", describeFilter(filter)),
+			})
+		}
+	}
+	for i, filter := range filterConfig.Filters {
+		location := fmt.Sprintf("filters[%d]", i)
+		switch {
+		case len(filterMatches[i]) == 0 && len(shadowed[i]) > 0:
+			report.add(Finding{
+				Severity:  SeverityError,
+				Location:  location,
+				Message:   fmt.Sprintf("%s only matches resources that are excluded or selected by an earlier filter or global resource type", describeFilter(filter)),
+				Addresses: shadowed[i],
+			})
+		case len(filterMatches[i]) == 0:
+			report.add(Finding{
+				Severity: SeverityError,
+				Location: location,
+				Message:  fmt.Sprintf("%s does not match any resource", describeFilter(filter)),
+			})
+		case len(filterMatches[i]) > 1:
+			report.add(Finding{
+				Severity:  SeverityWarning,
+				Location:  location,
+				Message:   fmt.Sprintf("%s matches %d resources", describeFilter(filter), len(filterMatches[i])),
+				Addresses: filterMatches[i],
+			})
+		}
+	}
+
+	result, err := FilterStateResources(resources, CopyResourceFilterFunc, filterConfig)
+	if err != nil {
+		report.add(Finding{Severity: SeverityError, Message: err.Error()})
+		return report
+	}
+	report.Selected = len(result.Resources)
+	lintRenames(report, resources, result)
+	return report
+}
+
+// lintRenames reports resources renamed to the address of another resource of the state, or to the
+// same address as another copied resource
+func lintRenames(report *Report, resources []models.Resource, result *Result) {
+	existing := make(map[string]bool, len(resources))
+	for _, resource := range resources {
+		existing[resource.Address()] = true
+	}
+	counts := make(map[string]int, len(result.Resources))
+	for _, resource := range result.Resources {
+		counts[resource.Address()]++
+	}
+	renamedTo := make(map[string][]string)
+	for from, to := range result.Renames {
+		renamedTo[to] = append(renamedTo[to], from)
+	}
+
+	tos := make([]string, 0, len(renamedTo))
+	for to := range renamedTo {
+		tos = append(tos, to)
+	}
+	sort.Strings(tos)
+	for _, to := range tos {
+		froms := renamedTo[to]
+		sort.Strings(froms)
+		switch {
+		case counts[to] > 1:
+			addresses := froms
+			if counts[to] > len(froms) {
+				addresses = append(addresses, to)
+			}
+			report.add(Finding{
+				Severity:  SeverityError,
+				Message:   fmt.Sprintf("%d copied resources have the address %s", counts[to], to),
+				Addresses: addresses,
+			})
+		case existing[to]:
+			report.add(Finding{
+				Severity:  SeverityError,
+				Message:   fmt.Sprintf("%s is renamed to %s, which already exists in the state", froms[0], to),
+				Addresses: []string{froms[0], to},
+			})
+		}
+	}
+}
+
+func describeFilter(filter models.Filter) string {
+	if filter.Address != "" {
+		return fmt.Sprintf("filter %s", filter.Address)
+	}
+	properties := filter.FilterProperties
+	if properties.Mode == "data" {
+		return fmt.Sprintf("filter (mode \"data\", module %q, type %q, name %q)", properties.Module, properties.Type, properties.Name)
+	}
+	return fmt.Sprintf("filter (module %q, type %q, name %q)", properties.Module, properties.Type, properties.Name)
+}
+
+// Write prints the report in a human readable format
+func (r *Report) Write(w io.Writer) {
+	for _, finding := range r.Findings {
+		location := ""
+		if finding.Location != "" {
+			location = finding.Location + ": "
+		}
+		fmt.Fprintf(w, "%s: %s%s\n", finding.Severity, location, finding.Message)
+		for _, address := range finding.Addresses {
+			fmt.Fprintf(w, "  %s\n", address)
+		}
+	}
+	if len(r.Findings) > 0 {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "%d of %d resources selected, %d errors, %d warnings\n", r.Selected, r.Resources, r.Errors, r.Warnings)
+}
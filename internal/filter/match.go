@@ -0,0 +1,296 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mupuri/go-tfdr/internal/attrpath"
+	"github.com/mupuri/go-tfdr/internal/models"
+)
+
+// regexPrefix marks a filter property value as a regular expression
+const regexPrefix = "re:"
+
+var (
+	patterns   = make(map[string]*regexp.Regexp)
+	patternsMu sync.Mutex
+)
+
+// compilePattern compiles a filter property value into an anchored regular expression.
+// Values prefixed with re: are regular expressions. Values containing * or ? are globs
+// where each wildcard is a capture group. Wildcards do not match dots, so a glob never
+// crosses into a nested module. Anything else is matched literally.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	patternsMu.Lock()
+	defer patternsMu.Unlock()
+	if re, ok := patterns[pattern]; ok {
+		return re, nil
+	}
+
+	var expr string
+	if strings.HasPrefix(pattern, regexPrefix) {
+		expr = strings.TrimPrefix(pattern, regexPrefix)
+	} else {
+		var sb strings.Builder
+		for _, r := range pattern {
+			switch r {
+			case '*':
+				sb.WriteString("([^.]*)")
+			case '?':
+				sb.WriteString("([^.])")
+			default:
+				sb.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		expr = sb.String()
+	}
+
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("Invalid pattern %q. Err: %v", pattern, err)
+	}
+	patterns[pattern] = re
+	return re, nil
+}
+
+// matchPattern returns true if value matches pattern. Invalid patterns never match; they are
+// reported when the filter config is read.
+func matchPattern(pattern string, value string) bool {
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(value)
+}
+
+// expandPattern replaces $1, ${name} etc. in template with the groups captured when value
+// was matched against pattern
+func expandPattern(pattern string, value string, template string) string {
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return template
+	}
+	return re.ReplaceAllString(value, template)
+}
+
+// templateGroups returns the groups a replacement template refers to as $name or ${name}. Names are read
+// the way regexp.Regexp.Expand reads them, so $1_east refers to a group named 1_east.
+func templateGroups(template string) []string {
+	groups := make([]string, 0)
+	for i := 0; i < len(template)-1; i++ {
+		if template[i] != '$' {
+			continue
+		}
+		rest := template[i+1:]
+		if rest[0] == '$' {
+			i++
+			continue
+		}
+		braced := rest[0] == '{'
+		if braced {
+			rest = rest[1:]
+		}
+		n := 0
+		for n < len(rest) && isGroupNameChar(rest[n]) {
+			n++
+		}
+		if n == 0 || braced && (n == len(rest) || rest[n] != '}') {
+			continue
+		}
+		groups = append(groups, rest[:n])
+	}
+	return groups
+}
+
+func isGroupNameChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// validateNameTemplate checks that a new name only refers to groups captured by the name pattern
+func validateNameTemplate(location string, pattern string, template string) error {
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return err
+	}
+	for _, group := range templateGroups(template) {
+		if hasGroup(re, group) {
+			continue
+		}
+		message := fmt.Sprintf("New name %q of %s refers to group %q, which name pattern %q does not have", template, location, group, pattern)
+		if group[0] >= '0' && group[0] <= '9' {
+			message += ". Use ${1} to separate a group number from the text after it"
+		}
+		return errors.New(message)
+	}
+	return nil
+}
+
+// hasGroup returns true if re has the group with the number or name group
+func hasGroup(re *regexp.Regexp, group string) bool {
+	if n, err := strconv.Atoi(group); err == nil {
+		return n <= re.NumSubexp()
+	}
+	for _, name := range re.SubexpNames() {
+		if name == group {
+			return true
+		}
+	}
+	return false
+}
+
+func matchFilterProperties(resource *models.Resource, properties models.FilterProperties) bool {
+	return resource.Mode == filterMode(properties) &&
+		matchModule(properties.Module, resource.Module) &&
+		matchPattern(properties.Type, resource.Type) &&
+		matchPattern(properties.Name, resource.Name)
+}
+
+// filterMode returns the mode of the resources selected by properties
+func filterMode(properties models.FilterProperties) string {
+	if properties.Mode == "" {
+		return "managed"
+	}
+	return properties.Mode
+}
+
+// matchModule returns true if the module path of a resource matches pattern. Besides matching the
+// whole path like matchPattern, paths are matched module by module: a module without an instance key
+// matches every instance of the module, so module.app matches module.app["east"], while
+// module.app["east"] only matches that instance.
+func matchModule(pattern string, module string) bool {
+	if matchPattern(pattern, module) {
+		return true
+	}
+	if strings.HasPrefix(pattern, regexPrefix) {
+		return false
+	}
+
+	patternSteps, ok := splitModulePath(pattern)
+	if !ok {
+		return false
+	}
+	moduleSteps, ok := splitModulePath(module)
+	if !ok || len(patternSteps) != len(moduleSteps) {
+		return false
+	}
+	for i, step := range patternSteps {
+		if !matchPattern(step.name, moduleSteps[i].name) {
+			return false
+		}
+		if step.key == "" {
+			continue
+		}
+		if moduleSteps[i].key == "" {
+			return false
+		}
+		patternKey, _ := parseIndexKey(step.key)
+		moduleKey, _ := parseIndexKey(moduleSteps[i].key)
+		if patternKey != moduleKey {
+			return false
+		}
+	}
+	return true
+}
+
+// splitModulePath splits a module path such as module.app["east"].module.db into the names and
+// instance keys of its modules
+func splitModulePath(path string) ([]addressStep, bool) {
+	if path == "" {
+		return nil, true
+	}
+	steps, err := splitAddress(path)
+	if err != nil || len(steps)%2 != 0 {
+		return nil, false
+	}
+	modules := make([]addressStep, 0, len(steps)/2)
+	for i := 0; i < len(steps); i += 2 {
+		if steps[i].name != "module" || steps[i].key != "" {
+			return nil, false
+		}
+		modules = append(modules, steps[i+1])
+	}
+	return modules, true
+}
+
+func validateFilterConfig(filterConfig *models.FilterConfig) error {
+	if mode := filterConfig.Mode; mode != "" && mode != models.FilterModeInclude && mode != models.FilterModeExclude {
+		return fmt.Errorf("Invalid mode %q. Expected include or exclude", mode)
+	}
+	patterns := append([]string{}, filterConfig.GlobalResourceTypes...)
+	for i, filter := range filterConfig.Filters {
+		filterPatterns, err := validateFilter(fmt.Sprintf("filters[%d]", i), filter)
+		if err != nil {
+			return err
+		}
+		patterns = append(patterns, filterPatterns...)
+	}
+	for i, filter := range filterConfig.Exclude {
+		location := fmt.Sprintf("exclude[%d]", i)
+		newProperties := filter.NewProperties
+		if newProperties.Name != "" || newProperties.Provider != "" || len(newProperties.Attributes) > 0 || len(newProperties.IndexKeys) > 0 {
+			return fmt.Errorf("%s sets new_properties. Excluded resources cannot be changed", location)
+		}
+		filterPatterns, err := validateFilter(location, filter)
+		if err != nil {
+			return err
+		}
+		patterns = append(patterns, filterPatterns...)
+	}
+	patterns = append(patterns, filterConfig.Outputs.Include...)
+	patterns = append(patterns, filterConfig.Outputs.Exclude...)
+	for _, replacement := range filterConfig.Replacements {
+		patterns = append(patterns, replacement.ResourceTypes...)
+		if _, err := newReplacer(replacement); err != nil {
+			return err
+		}
+	}
+	for _, pattern := range patterns {
+		if _, err := compilePattern(pattern); err != nil {
+			return err
+		}
+	}
+	if err := validateProviders(filterConfig); err != nil {
+		return err
+	}
+	for _, filter := range filterConfig.Filters {
+		for path := range filter.NewProperties.Attributes {
+			if _, err := attrpath.Parse(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateFilter validates the selector of a filter and returns its patterns
+func validateFilter(location string, filter models.Filter) ([]string, error) {
+	properties := filter.FilterProperties
+	if mode := properties.Mode; mode != "" && mode != "managed" && mode != "data" {
+		return nil, fmt.Errorf("Invalid mode %q of %s. Expected managed or data", mode, location)
+	}
+	if filter.Address != "" {
+		if properties.Mode != "" || properties.Module != "" || properties.Type != "" || properties.Name != "" {
+			return nil, fmt.Errorf("%s sets both address and filter_properties", location)
+		}
+		sel, err := parseAddress(filter.Address)
+		if err != nil {
+			return nil, err
+		}
+		properties = sel.properties
+	}
+	if filter.NewProperties.Name != "" {
+		if err := validateNameTemplate(location, properties.Name, filter.NewProperties.Name); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range filter.FilterProperties.Where {
+		if _, err := compilePredicate(p); err != nil {
+			return nil, err
+		}
+	}
+	return []string{properties.Module, properties.Type, properties.Name}, nil
+}
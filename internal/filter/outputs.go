@@ -0,0 +1,58 @@
+package filter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mupuri/go-tfdr/internal/models"
+)
+
+// FilterOutputs returns the outputs selected by outputFilter, renamed and with their values overridden.
+// Renaming or overriding an output that is not in outputs is an error.
+func FilterOutputs(outputs map[string]models.Output, outputFilter models.OutputFilter) (map[string]models.Output, map[string]string, error) {
+	for _, name := range sortedKeys(outputFilter.Rename) {
+		if _, ok := outputs[name]; !ok {
+			return nil, nil, fmt.Errorf("Unable to rename output %s. Output does not exist", name)
+		}
+	}
+	for name := range outputFilter.Values {
+		if _, ok := outputs[name]; !ok {
+			return nil, nil, fmt.Errorf("Unable to override output %s. Output does not exist", name)
+		}
+	}
+
+	filtered := make(map[string]models.Output)
+	renames := make(map[string]string)
+	for name, output := range outputs {
+		if len(outputFilter.Include) > 0 && !matchAny(outputFilter.Include, name) {
+			continue
+		}
+		if matchAny(outputFilter.Exclude, name) {
+			continue
+		}
+
+		output.Value = copyValue(output.Value)
+		if value, ok := outputFilter.Values[name]; ok {
+			output.Value = copyValue(value)
+		}
+		newName := name
+		if n, ok := outputFilter.Rename[name]; ok {
+			newName = n
+			renames[name] = n
+		}
+		if _, ok := filtered[newName]; ok {
+			return nil, nil, fmt.Errorf("Unable to rename output %s. Output %s already exists", name, newName)
+		}
+		filtered[newName] = output
+	}
+	return filtered, renames, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,148 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mupuri/go-tfdr/internal/models"
+)
+
+const (
+	defaultProviderHostname  = "registry.terraform.io"
+	defaultProviderNamespace = "hashicorp"
+)
+
+var (
+	providerConfigRegex = regexp.MustCompile(`^provider\["([^"]+)"\](?:\.([A-Za-z0-9_-]+))?$`)
+	legacyProviderRegex = regexp.MustCompile(`^provider\.([A-Za-z0-9_-]+)(?:\.([A-Za-z0-9_-]+))?$`)
+)
+
+type providerAddress struct {
+	hostname  string
+	namespace string
+	typ       string
+	alias     string
+}
+
+func (p providerAddress) String() string {
+	s := fmt.Sprintf(`provider["%s/%s/%s"]`, p.hostname, p.namespace, p.typ)
+	if p.alias != "" {
+		s += "." + p.alias
+	}
+	return s
+}
+
+// parseProviderAddress parses the provider of a resource in state, e.g. provider["registry.terraform.io/hashicorp/aws"].dr
+// or provider.aws.dr, as well as the short forms aws, hashicorp/aws.dr and registry.terraform.io/hashicorp/aws.
+func parseProviderAddress(address string) (providerAddress, error) {
+	var source, alias string
+	if m := providerConfigRegex.FindStringSubmatch(address); m != nil {
+		source, alias = m[1], m[2]
+	} else if m := legacyProviderRegex.FindStringSubmatch(address); m != nil {
+		source, alias = m[1], m[2]
+	} else {
+		source = address
+		parts := strings.Split(address, "/")
+		last := parts[len(parts)-1]
+		if i := strings.Index(last, "."); i >= 0 {
+			source = strings.TrimSuffix(address, last[i:])
+			alias = last[i+1:]
+		}
+	}
+
+	p := providerAddress{
+		hostname:  defaultProviderHostname,
+		namespace: defaultProviderNamespace,
+		alias:     alias,
+	}
+	parts := strings.Split(source, "/")
+	switch len(parts) {
+	case 1:
+		p.typ = parts[0]
+	case 2:
+		p.namespace, p.typ = parts[0], parts[1]
+	case 3:
+		p.hostname, p.namespace, p.typ = parts[0], parts[1], parts[2]
+	}
+	if len(parts) > 3 || p.hostname == "" || p.namespace == "" || p.typ == "" {
+		return providerAddress{}, fmt.Errorf("Invalid provider address %q", address)
+	}
+	return p, nil
+}
+
+// formatProviderAddress returns address unchanged if it is already written the way terraform writes
+// providers in state, otherwise its full provider["<hostname>/<namespace>/<type>"] form
+func formatProviderAddress(address string) (string, error) {
+	if providerConfigRegex.MatchString(address) || legacyProviderRegex.MatchString(address) {
+		return address, nil
+	}
+	p, err := parseProviderAddress(address)
+	if err != nil {
+		return "", err
+	}
+	return p.String(), nil
+}
+
+// rewriteProvider sets the provider of resource to newProvider if it is set, otherwise to the
+// provider it is mapped to in providers
+func rewriteProvider(resource *models.Resource, providers map[string]string, newProvider string) error {
+	if newProvider != "" {
+		provider, err := formatProviderAddress(newProvider)
+		if err != nil {
+			return err
+		}
+		resource.Provider = provider
+		return nil
+	}
+	if len(providers) == 0 || resource.Provider == "" {
+		return nil
+	}
+
+	current, err := parseProviderAddress(resource.Provider)
+	if err != nil {
+		return err
+	}
+	for from, to := range providers {
+		p, err := parseProviderAddress(from)
+		if err != nil {
+			return err
+		}
+		if p == current {
+			provider, err := formatProviderAddress(to)
+			if err != nil {
+				return err
+			}
+			resource.Provider = provider
+			return nil
+		}
+	}
+	return nil
+}
+
+func validateProviders(filterConfig *models.FilterConfig) error {
+	addresses := make([]string, 0)
+	mapped := make(map[providerAddress]string)
+	for from, to := range filterConfig.Providers {
+		p, err := parseProviderAddress(from)
+		if err != nil {
+			return err
+		}
+		if other, ok := mapped[p]; ok {
+			return fmt.Errorf("Providers %q and %q refer to the same provider", other, from)
+		}
+		mapped[p] = from
+		addresses = append(addresses, to)
+	}
+	for _, filter := range filterConfig.Filters {
+		if filter.NewProperties.Provider != "" {
+			addresses = append(addresses, filter.NewProperties.Provider)
+		}
+	}
+	for _, address := range addresses {
+		if _, err := parseProviderAddress(address); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mupuri/go-tfdr/internal/models"
+)
+
+type replacer func(string) string
+
+func newReplacer(replacement models.Replacement) (replacer, error) {
+	if replacement.Find == "" {
+		return nil, fmt.Errorf("Replacement find value is empty")
+	}
+	if !replacement.Regex {
+		return func(s string) string {
+			return strings.ReplaceAll(s, replacement.Find, replacement.Replace)
+		}, nil
+	}
+	re, err := regexp.Compile(replacement.Find)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid replacement regex %q. Err: %v", replacement.Find, err)
+	}
+	return func(s string) string {
+		return re.ReplaceAllString(s, replacement.Replace)
+	}, nil
+}
+
+// ApplyReplacements runs each replacement against every string attribute value of resources, in order.
+// It returns the number of values each replacement changed.
+func ApplyReplacements(resources []models.Resource, replacements []models.Replacement) ([]int, error) {
+	counts := make([]int, len(replacements))
+	for i, replacement := range replacements {
+		replace, err := newReplacer(replacement)
+		if err != nil {
+			return nil, err
+		}
+		for r := range resources {
+			resource := &resources[r]
+			if len(replacement.ResourceTypes) > 0 && !matchAny(replacement.ResourceTypes, resource.Type) {
+				continue
+			}
+			for _, instance := range resource.Instances {
+				for k, v := range instance.Attributes {
+					instance.Attributes[k] = replaceValue(v, replace, &counts[i])
+				}
+			}
+		}
+	}
+	return counts, nil
+}
+
+func replaceValue(v interface{}, replace replacer, count *int) interface{} {
+	switch t := v.(type) {
+	case string:
+		replaced := replace(t)
+		if replaced != t {
+			*count++
+		}
+		return replaced
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = replaceValue(e, replace, count)
+		}
+		return t
+	case []interface{}:
+		for i, e := range t {
+			t[i] = replaceValue(e, replace, count)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
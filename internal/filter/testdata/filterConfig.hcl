@@ -0,0 +1,38 @@
+global_resource_types = [
+  "aws_cloudfront_distribution",
+  "aws_cloudfront_origin_access_identity",
+  "aws_iam_access_key",
+  "aws_iam_policy_document",
+  "aws_iam_policy",
+  "aws_iam_role_policy_attachment",
+  "aws_iam_role_policy",
+  "aws_iam_role",
+  "aws_iam_user_policy",
+  "aws_iam_user",
+  "aws_route53_record",
+]
+
+filters {
+  filter_properties {
+    module = "module.test_module_1"
+    type   = "type_1"
+    name   = "orig_name_1"
+  }
+  new_properties {
+    name = "new_name_1"
+  }
+}
+
+filters {
+  filter_properties {
+    module = "module.test_module_2"
+    type   = "type_2"
+    name   = "orig_name_2"
+  }
+  new_properties {
+    attributes = {
+      attr1 = "new_value_2"
+      attr2 = ""
+    }
+  }
+}
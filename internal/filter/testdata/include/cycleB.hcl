@@ -0,0 +1 @@
+include = ["cycleA.json"]
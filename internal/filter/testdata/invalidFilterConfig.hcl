@@ -0,0 +1,6 @@
+filters {
+  filter_properties {
+    module = "module.test_module_1"
+    type = "type_1
+  }
+}
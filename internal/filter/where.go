@@ -0,0 +1,97 @@
+package filter
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/mupuri/go-tfdr/internal/attrpath"
+	"github.com/mupuri/go-tfdr/internal/models"
+)
+
+// predicate is a compiled models.Predicate
+type predicate struct {
+	path  attrpath.Path
+	match func(values []interface{}) bool
+}
+
+// compilePredicate compiles a where predicate. Scalars are compared by their string form, so
+// "true" equals true and "1" equals 1. Regular expressions must match the whole value.
+func compilePredicate(p models.Predicate) (*predicate, error) {
+	path, err := attrpath.Parse(p.Attribute)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid where predicate. Err: %v", err)
+	}
+
+	operators := make([]func(values []interface{}) bool, 0, 1)
+	if p.Equals != nil {
+		operators = append(operators, func(values []interface{}) bool {
+			return containsValue(values, p.Equals)
+		})
+	}
+	if p.NotEquals != nil {
+		operators = append(operators, func(values []interface{}) bool {
+			return !containsValue(values, p.NotEquals)
+		})
+	}
+	if p.Regex != "" {
+		if _, err := compilePattern(regexPrefix + p.Regex); err != nil {
+			return nil, fmt.Errorf("Invalid where predicate on %s. Err: %v", p.Attribute, err)
+		}
+		operators = append(operators, func(values []interface{}) bool {
+			for _, value := range values {
+				if isScalar(value) && matchPattern(regexPrefix+p.Regex, fmt.Sprint(value)) {
+					return true
+				}
+			}
+			return false
+		})
+	}
+	if p.Exists != nil {
+		operators = append(operators, func(values []interface{}) bool {
+			return (len(values) > 0) == *p.Exists
+		})
+	}
+	if p.In != nil {
+		operators = append(operators, func(values []interface{}) bool {
+			for _, v := range p.In {
+				if containsValue(values, v) {
+					return true
+				}
+			}
+			return false
+		})
+	}
+	if len(operators) != 1 {
+		return nil, fmt.Errorf("Invalid where predicate on %s. Expected one of equals, not_equals, regex, exists or in", p.Attribute)
+	}
+	return &predicate{path: path, match: operators[0]}, nil
+}
+
+func (p *predicate) matches(instance models.Instance) bool {
+	return p.match(attrpath.Get(instance.Attributes, p.path))
+}
+
+func containsValue(values []interface{}, expected interface{}) bool {
+	for _, value := range values {
+		if equalValues(value, expected) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalValues(a interface{}, b interface{}) bool {
+	if isScalar(a) && isScalar(b) {
+		return fmt.Sprint(a) == fmt.Sprint(b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case string, bool, float64, int:
+		return true
+	default:
+		return false
+	}
+}
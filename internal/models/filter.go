@@ -1,6 +1,9 @@
 package models
 
 type Filter struct {
+	// Address selects resources by their terraform address instead of FilterProperties, e.g.
+	// module.app.aws_s3_bucket.logs["primary"] or data.aws_ami.base
+	Address          string           `json:"address"`
 	FilterProperties FilterProperties `json:"filter_properties"`
 	NewProperties    NewProperties    `json:"new_properties"`
 }
@@ -1,6 +1,24 @@
 package models
 
+// Modes of a filter config
+const (
+	// FilterModeInclude selects the global resource types and the resources matched by filters
+	FilterModeInclude = "include"
+	// FilterModeExclude selects every resource. Filters only change the resources they match.
+	FilterModeExclude = "exclude"
+)
+
 type FilterConfig struct {
+	// Include lists filter config files merged into this one. Relative paths are relative to this file.
+	Include []string `json:"include"`
+	// Mode is include or exclude. Empty means include.
+	Mode                string   `json:"mode"`
 	GlobalResourceTypes []string `json:"global_resource_types"`
 	Filters             []Filter `json:"filters"`
+	// Exclude lists resources that are never selected, whatever the mode, global resource types and filters
+	Exclude      []Filter      `json:"exclude"`
+	Replacements []Replacement `json:"replacements"`
+	Outputs      OutputFilter  `json:"outputs"`
+	// Providers maps the provider of copied resources to a new provider, e.g. to another alias or namespace
+	Providers map[string]string `json:"providers"`
 }
@@ -1,7 +1,13 @@
 package models
 
+// FilterProperties selects resources by module, type and name. Each value is matched literally
+// unless it contains the wildcards * or ?, or is a regular expression prefixed with re:
 type FilterProperties struct {
-	Module string `json:"module"` // Need to be able to search by wildcard
+	// Mode is the mode of the selected resources, managed or data. Empty means managed.
+	Mode   string `json:"mode"`
+	Module string `json:"module"`
 	Type   string `json:"type"`
 	Name   string `json:"name"`
+	// Where selects the instances whose attributes match every predicate
+	Where []Predicate `json:"where"`
 }
@@ -3,4 +3,9 @@ package models
 type NewProperties struct {
 	Name       string                 `json:"name"`
 	Attributes map[string]interface{} `json:"attributes"`
+	// Provider replaces the provider of the resource, overriding FilterConfig.Providers
+	Provider string `json:"provider"`
+	// IndexKeys limits attribute overrides to the instances with these count indexes or for_each keys.
+	// Overrides apply to every instance when empty.
+	IndexKeys []interface{} `json:"index_keys"`
 }
@@ -0,0 +1,8 @@
+package models
+
+// Output is a root module output value in state
+type Output struct {
+	Value     interface{} `json:"value"`
+	Type      interface{} `json:"type"`
+	Sensitive bool        `json:"sensitive,omitempty"`
+}
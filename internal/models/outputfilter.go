@@ -0,0 +1,10 @@
+package models
+
+// OutputFilter selects and rewrites the root module outputs copied to the new state. Every output is
+// copied when Include is empty.
+type OutputFilter struct {
+	Include []string               `json:"include"`
+	Exclude []string               `json:"exclude"`
+	Rename  map[string]string      `json:"rename"`
+	Values  map[string]interface{} `json:"values"`
+}
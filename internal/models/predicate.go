@@ -0,0 +1,13 @@
+package models
+
+// Predicate compares the value of an instance attribute. Exactly one of Equals, NotEquals, Regex,
+// Exists and In is set.
+type Predicate struct {
+	// Attribute is the path of the attribute, e.g. zone_id or tags.dr
+	Attribute string        `json:"attribute"`
+	Equals    interface{}   `json:"equals"`
+	NotEquals interface{}   `json:"not_equals"`
+	Regex     string        `json:"regex"`
+	Exists    *bool         `json:"exists"`
+	In        []interface{} `json:"in"`
+}
@@ -0,0 +1,11 @@
+package models
+
+// Replacement replaces text in every string attribute value of the copied resources
+type Replacement struct {
+	Find    string `json:"find"`
+	Replace string `json:"replace"`
+	// Regex treats Find as a regular expression. Replace can then refer to captured groups, e.g. $1
+	Regex bool `json:"regex"`
+	// ResourceTypes limits the replacement to resources of these types. Wildcards are allowed.
+	ResourceTypes []string `json:"resource_types"`
+}
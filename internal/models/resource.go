@@ -1,5 +1,10 @@
 package models
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Resource struct {
 	Module    string     `json:"module"`
 	Mode      string     `json:"mode"`
@@ -8,3 +13,29 @@ type Resource struct {
 	Provider  string     `json:"provider"`
 	Instances []Instance `json:"instances"`
 }
+
+// Address returns the full terraform address of the resource, e.g. module.a.data.aws_ami.base
+func (r Resource) Address() string {
+	parts := make([]string, 0, 4)
+	if r.Module != "" {
+		parts = append(parts, r.Module)
+	}
+	if r.Mode == "data" {
+		parts = append(parts, "data")
+	}
+	parts = append(parts, r.Type, r.Name)
+	return strings.Join(parts, ".")
+}
+
+// InstanceAddress returns the full terraform address of an instance of the resource, e.g. aws_instance.web[0]
+// or aws_s3_bucket.logs["primary"]
+func (r Resource) InstanceAddress(instance Instance) string {
+	switch k := instance.IndexKey.(type) {
+	case nil:
+		return r.Address()
+	case string:
+		return fmt.Sprintf("%s[%q]", r.Address(), k)
+	default:
+		return fmt.Sprintf("%s[%v]", r.Address(), k)
+	}
+}
@@ -36,11 +36,20 @@ func NewState() *models.State {
 		TerraformVersion: DefaultTerraformVersion,
 		Serial:           DefaultSerial,
 		Lineage:          DefaultLineage,
-		Outputs:          nil,
+		Outputs:          NewStateOutputs(),
 		Resources:        NewStateResources(),
 	}
 }
 
+// NewStateOutputs &
+func NewStateOutputs() map[string]models.Output {
+	return map[string]models.Output{
+		"bucket_name": {Value: "bucket", Type: "string"},
+		"db_password": {Value: "password", Type: "string", Sensitive: true},
+		"subnet_ids":  {Value: []interface{}{"subnet-1", "subnet-2"}, Type: []interface{}{"list", "string"}},
+	}
+}
+
 // DefaultNumResources &
 func DefaultNumResources() int {
 	return defaultNonGlobalResources + len(GlobalResources)
@@ -89,6 +98,16 @@ func SetupWksMockHTTPResponses(wks *TfeTestWks) error {
 				fmt.Sprintf("https://app.terraform.io/api/v2/organizations/team/workspaces/%v", wks.Name),
 				NewResponder(wks.Name, "workspaces", ""),
 			)
+			httpmock.RegisterResponder(
+				"POST",
+				fmt.Sprintf("https://app.terraform.io/api/v2/workspaces/%v/actions/lock", wks.Name),
+				NewResponder(wks.Name, "workspaces", ""),
+			)
+			httpmock.RegisterResponder(
+				"POST",
+				fmt.Sprintf("https://app.terraform.io/api/v2/workspaces/%v/actions/unlock", wks.Name),
+				NewResponder(wks.Name, "workspaces", ""),
+			)
 			if wks.CsvResponder != nil {
 				httpmock.RegisterResponder(
 					"GET",
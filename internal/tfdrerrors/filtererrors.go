@@ -7,5 +7,19 @@ type ErrReadFilterFile struct {
 }
 
 func (errReadFilterFile ErrReadFilterFile) Error() string {
-	return fmt.Sprintf("Unable to get workspace. Err: %v", errReadFilterFile.Err)
+	return fmt.Sprintf("Unable to read filter file. Err: %v", errReadFilterFile.Err)
+}
+
+type ErrNoResourcesMatched struct{}
+
+func (ErrNoResourcesMatched) Error() string {
+	return "filter did not match any resources"
+}
+
+type ErrInvalidFilter struct {
+	Errors int
+}
+
+func (errInvalidFilter ErrInvalidFilter) Error() string {
+	return fmt.Sprintf("filter config has %d errors", errInvalidFilter.Errors)
 }
@@ -54,6 +54,15 @@ func (errUnableToGetStateVersion ErrUnableToGetStateVersion) Error() string {
 	return fmt.Sprintf("Cannot get current state. Error: %v", errUnableToGetStateVersion.Err)
 }
 
+type ErrStateVersionNotInWorkspace struct {
+	StateVersionID string
+	Workspace      string
+}
+
+func (err ErrStateVersionNotInWorkspace) Error() string {
+	return fmt.Sprintf("State version %s is not in the history of workspace %s", err.StateVersionID, err.Workspace)
+}
+
 type ErrUnableToDownloadState struct {
 	Err error
 }
@@ -61,3 +70,51 @@ type ErrUnableToDownloadState struct {
 func (errUnableToDownloadState ErrUnableToDownloadState) Error() string {
 	return fmt.Sprintf("Cannot download state. Error: %v", errUnableToDownloadState.Err)
 }
+
+type ErrUnableToBackupState struct {
+	Err error
+}
+
+func (errUnableToBackupState ErrUnableToBackupState) Error() string {
+	return fmt.Sprintf("Unable to back up state. Error: %v", errUnableToBackupState.Err)
+}
+
+type ErrRestoreCancelled struct{}
+
+func (ErrRestoreCancelled) Error() string {
+	return "restore cancelled"
+}
+
+type ErrUnableToLockState struct {
+	Err error
+}
+
+func (errUnableToLockState ErrUnableToLockState) Error() string {
+	return fmt.Sprintf("Unable to lock state. Error: %v", errUnableToLockState.Err)
+}
+
+type ErrUnableToConnect struct {
+	Address string
+	Err     error
+}
+
+func (errUnableToConnect ErrUnableToConnect) Error() string {
+	return fmt.Sprintf("Unable to connect to %s. Check the address, CA bundle and proxy settings. Error: %v", errUnableToConnect.Address, errUnableToConnect.Err)
+}
+
+type ErrInvalidToken struct {
+	Err error
+}
+
+func (errInvalidToken ErrInvalidToken) Error() string {
+	return fmt.Sprintf("Team token was rejected. Check that it is valid and has not been revoked. Error: %v", errInvalidToken.Err)
+}
+
+type ErrOrganizationNotAccessible struct {
+	Org string
+	Err error
+}
+
+func (errOrganizationNotAccessible ErrOrganizationNotAccessible) Error() string {
+	return fmt.Sprintf("Organization %s does not exist or the team token has no access to it. Error: %v", errOrganizationNotAccessible.Org, errOrganizationNotAccessible.Err)
+}
@@ -1,9 +1,15 @@
 package main
 
-import "github.com/mupuri/go-tfdr/cmd"
+import (
+	"os"
+
+	"github.com/mupuri/go-tfdr/cmd"
+)
 
 var version = "devbuild"
 
 func main() {
-	cmd.Execute(version)
+	if err := cmd.Execute(version); err != nil {
+		os.Exit(1)
+	}
 }